@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Default scrypt cost parameters for newly encrypted keypair files. N must
+// be a power of two; these match common scrypt defaults for interactive
+// password-derived keys.
+const (
+	defaultScryptN = 1 << 15
+	defaultScryptR = 8
+	defaultScryptP = 1
+
+	scryptKeyLen       = 32
+	secretboxNonceSize = 24
+)
+
+// encryptedKeypairFile is the on-disk JSON shape of a passphrase-protected
+// keypair, compatible with solana-keygen's encrypted keypair format: the
+// keypair bytes are sealed with a NaCl secretbox whose key is derived from
+// the passphrase via scrypt.
+type encryptedKeypairFile struct {
+	Encrypted []byte `json:"encrypted"`
+	IV        []byte `json:"iv"`
+	Salt      []byte `json:"salt"`
+	KDF       string `json:"kdf"`
+	N         int    `json:"n"`
+	R         int    `json:"r"`
+	P         int    `json:"p"`
+}
+
+// looksLikeEncryptedKeypair reports whether data is a JSON object (the
+// encrypted format) rather than a plain JSON array of keypair bytes.
+func looksLikeEncryptedKeypair(data []byte) bool {
+	for _, b := range data {
+		switch b {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '{':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// decryptKeypairFile derives a key from passphrase via scrypt and opens the
+// sealed keypair bytes with NaCl secretbox, returning the resulting
+// ed25519 keypair.
+func decryptKeypairFile(data []byte, passphrase string) (ed25519.PrivateKey, error) {
+	var enc encryptedKeypairFile
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return nil, fmt.Errorf("failed to parse encrypted keypair JSON: %w", err)
+	}
+
+	if enc.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation function: %q", enc.KDF)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase required to decrypt keypair (use -passphrase-stdin or -passphrase-env)")
+	}
+	if len(enc.IV) != secretboxNonceSize {
+		return nil, fmt.Errorf("invalid nonce length: expected %d bytes, got %d", secretboxNonceSize, len(enc.IV))
+	}
+
+	derivedKey, err := scrypt.Key([]byte(passphrase), enc.Salt, enc.N, enc.R, enc.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive decryption key: %w", err)
+	}
+
+	var secretKey [scryptKeyLen]byte
+	copy(secretKey[:], derivedKey)
+	var nonce [secretboxNonceSize]byte
+	copy(nonce[:], enc.IV)
+
+	decrypted, ok := secretbox.Open(nil, enc.Encrypted, &nonce, &secretKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to decrypt keypair: wrong passphrase or tampered ciphertext")
+	}
+	if len(decrypted) != 64 {
+		return nil, fmt.Errorf("invalid decrypted keypair length: expected 64 bytes, got %d", len(decrypted))
+	}
+
+	return ed25519.NewKeyFromSeed(decrypted[:32]), nil
+}
+
+// encryptKeypairFile seals keypair's 64 raw bytes with a fresh random salt
+// and nonce, using a scrypt-derived key from passphrase, and returns the
+// resulting encryptedKeypairFile JSON document.
+func encryptKeypairFile(keypair ed25519.PrivateKey, passphrase string) ([]byte, error) {
+	if len(keypair) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid keypair length: expected %d bytes, got %d", ed25519.PrivateKeySize, len(keypair))
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	var nonce [secretboxNonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, defaultScryptN, defaultScryptR, defaultScryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+	var secretKey [scryptKeyLen]byte
+	copy(secretKey[:], derivedKey)
+
+	encrypted := secretbox.Seal(nil, keypair, &nonce, &secretKey)
+
+	doc := encryptedKeypairFile{
+		Encrypted: encrypted,
+		IV:        nonce[:],
+		Salt:      salt,
+		KDF:       "scrypt",
+		N:         defaultScryptN,
+		R:         defaultScryptR,
+		P:         defaultScryptP,
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// resolveKeypairPassphrase resolves the passphrase used to decrypt or
+// encrypt a keypair file from -passphrase-stdin or -passphrase-env. If
+// neither is set, it returns an empty passphrase, which is correct for
+// plain (unencrypted) keypair files.
+func resolveKeypairPassphrase(stdin bool, envVar string) (string, error) {
+	switch {
+	case stdin:
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read passphrase from stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	case envVar != "":
+		value, ok := os.LookupEnv(envVar)
+		if !ok || value == "" {
+			return "", fmt.Errorf("environment variable %s is not set", envVar)
+		}
+		return value, nil
+	default:
+		return "", nil
+	}
+}
+
+// runEncryptKeypairOutCommand implements -encrypt-keypair-out: it loads the
+// plain keypair at keypairPath, encrypts it with passphrase, and writes the
+// resulting encrypted keypair JSON to outPath.
+func runEncryptKeypairOutCommand(keypairPath, passphrase, outPath string) {
+	if keypairPath == "" {
+		log.Fatalf("Error: -keypair must be provided with -encrypt-keypair-out")
+	}
+	if passphrase == "" {
+		log.Fatalf("Error: a passphrase is required to encrypt a keypair (use -passphrase-stdin or -passphrase-env)")
+	}
+
+	keypair, err := loadKeypairFromFile(expandHomeDir(keypairPath), "")
+	if err != nil {
+		log.Fatalf("Failed to load keypair: %v", err)
+	}
+
+	encrypted, err := encryptKeypairFile(keypair, passphrase)
+	if err != nil {
+		log.Fatalf("Failed to encrypt keypair: %v", err)
+	}
+
+	if err := os.WriteFile(outPath, encrypted, 0600); err != nil {
+		log.Fatalf("Failed to write encrypted keypair file: %v", err)
+	}
+
+	fmt.Printf("Encrypted keypair written to %s\n", outPath)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func testEncryptionKeypair(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate test keypair: %v", err)
+	}
+	return priv
+}
+
+func TestEncryptAndDecryptKeypairRoundTrip(t *testing.T) {
+	priv := testEncryptionKeypair(t)
+
+	encrypted, err := encryptKeypairFile(priv, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("encryptKeypairFile failed: %v", err)
+	}
+	if !looksLikeEncryptedKeypair(encrypted) {
+		t.Fatalf("expected encrypted keypair to look like a JSON object")
+	}
+
+	decrypted, err := decryptKeypairFile(encrypted, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("decryptKeypairFile failed: %v", err)
+	}
+	if !priv.Equal(decrypted) {
+		t.Error("expected decrypted keypair to match the original")
+	}
+}
+
+func TestDecryptKeypairFileWrongPassphrase(t *testing.T) {
+	priv := testEncryptionKeypair(t)
+
+	encrypted, err := encryptKeypairFile(priv, "right passphrase")
+	if err != nil {
+		t.Fatalf("encryptKeypairFile failed: %v", err)
+	}
+
+	if _, err := decryptKeypairFile(encrypted, "wrong passphrase"); err == nil {
+		t.Error("expected error for wrong passphrase")
+	}
+}
+
+func TestDecryptKeypairFileTamperedCiphertext(t *testing.T) {
+	priv := testEncryptionKeypair(t)
+
+	encrypted, err := encryptKeypairFile(priv, "a passphrase")
+	if err != nil {
+		t.Fatalf("encryptKeypairFile failed: %v", err)
+	}
+
+	tampered := append([]byte(nil), encrypted...)
+	for i := len(tampered) - 2; i >= 0; i-- {
+		if tampered[i] >= '0' && tampered[i] <= '9' {
+			if tampered[i] == '9' {
+				tampered[i] = '0'
+			} else {
+				tampered[i]++
+			}
+			break
+		}
+	}
+
+	if _, err := decryptKeypairFile(tampered, "a passphrase"); err == nil {
+		t.Error("expected error for tampered ciphertext")
+	}
+}
+
+func TestDecryptKeypairFileNoPassphrase(t *testing.T) {
+	priv := testEncryptionKeypair(t)
+
+	encrypted, err := encryptKeypairFile(priv, "a passphrase")
+	if err != nil {
+		t.Fatalf("encryptKeypairFile failed: %v", err)
+	}
+
+	if _, err := decryptKeypairFile(encrypted, ""); err == nil {
+		t.Error("expected error when no passphrase is provided")
+	}
+}
+
+func TestDecryptKeypairFileUnsupportedKDF(t *testing.T) {
+	if _, err := decryptKeypairFile([]byte(`{"kdf":"bcrypt"}`), "x"); err == nil {
+		t.Error("expected error for unsupported KDF")
+	}
+}
+
+func TestLooksLikeEncryptedKeypair(t *testing.T) {
+	if looksLikeEncryptedKeypair([]byte("[1,2,3]")) {
+		t.Error("expected plain byte array to not look like an encrypted keypair")
+	}
+	if !looksLikeEncryptedKeypair([]byte("  {\"kdf\":\"scrypt\"}")) {
+		t.Error("expected JSON object (with leading whitespace) to look like an encrypted keypair")
+	}
+}
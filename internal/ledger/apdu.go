@@ -0,0 +1,108 @@
+// Package ledger speaks the Ledger hardware wallet APDU protocol over HID
+// to the Solana app, so go-sol-sign can sign with a device-held key instead
+// of loading one from disk.
+package ledger
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Solana app APDU constants. CLA identifies the Solana app; GET_PUBKEY
+// retrieves (and optionally displays) the public key for a derivation path,
+// SIGN_MESSAGE signs a message already staged on the device across one or
+// more chunks.
+const (
+	claSolana = 0xe0
+
+	insGetPubkey   = 0x05
+	insSignMessage = 0x06
+)
+
+// GET_PUBKEY / SIGN_MESSAGE P1 values. p1Confirm asks the device to display
+// the derived address and require physical approval; p1MoreChunks and
+// p1LastChunk mark SIGN_MESSAGE frames that continue or conclude a
+// multi-APDU message.
+const (
+	p1NoConfirm  = 0x00
+	p1Confirm    = 0x01
+	p1MoreChunks = 0x00
+	p1LastChunk  = 0x80
+)
+
+// maxChunkSize is the largest instruction data payload a single APDU frame
+// may carry, per the ISO 7816-4 Lc byte.
+const maxChunkSize = 255
+
+// statusWordError is returned when the device responds with anything other
+// than success (0x9000), translating well-known status words into
+// actionable messages.
+type statusWordError struct {
+	sw uint16
+}
+
+func (e *statusWordError) Error() string {
+	switch e.sw {
+	case 0x6985:
+		return "signing request rejected on device"
+	case 0x6982:
+		return "device is locked; unlock it and reopen the Solana app"
+	case 0x6e00:
+		return "Solana app is not open on the device"
+	case 0x6a80, 0x6a86:
+		return "device rejected the request: invalid derivation path or data"
+	default:
+		return fmt.Sprintf("device returned status word 0x%04x", e.sw)
+	}
+}
+
+// encodeDerivationPath serializes a BIP-32 path as the Solana app expects:
+// one byte giving the number of indices, followed by each index as a
+// big-endian uint32 (already carrying the hardened bit, since SLIP-0010
+// ed25519 requires every index to be hardened).
+func encodeDerivationPath(path []uint32) []byte {
+	out := make([]byte, 1+4*len(path))
+	out[0] = byte(len(path))
+	for i, idx := range path {
+		binary.BigEndian.PutUint32(out[1+4*i:1+4*i+4], idx)
+	}
+	return out
+}
+
+// buildAPDU assembles a single command APDU: CLA, INS, P1, P2, Lc, data.
+func buildAPDU(cla, ins, p1, p2 byte, data []byte) ([]byte, error) {
+	if len(data) > maxChunkSize {
+		return nil, fmt.Errorf("APDU data too long: %d bytes (max %d)", len(data), maxChunkSize)
+	}
+	apdu := make([]byte, 5, 5+len(data))
+	apdu[0] = cla
+	apdu[1] = ins
+	apdu[2] = p1
+	apdu[3] = p2
+	apdu[4] = byte(len(data))
+	return append(apdu, data...), nil
+}
+
+// chunkMessageWithPrefix splits message into APDU-sized pieces for
+// SIGN_MESSAGE, reserving prefixLen bytes of the first chunk's capacity for
+// the caller to prepend (the serialized derivation path). Later chunks use
+// the full maxChunkSize, matching how the device reassembles the stream.
+func chunkMessageWithPrefix(prefixLen int, message []byte) [][]byte {
+	firstCap := maxChunkSize - prefixLen
+	if len(message) == 0 {
+		return [][]byte{{}}
+	}
+
+	var chunks [][]byte
+	cap := firstCap
+	for len(message) > 0 {
+		n := cap
+		if n > len(message) {
+			n = len(message)
+		}
+		chunks = append(chunks, message[:n])
+		message = message[n:]
+		cap = maxChunkSize
+	}
+	return chunks
+}
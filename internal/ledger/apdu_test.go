@@ -0,0 +1,82 @@
+package ledger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeDerivationPath(t *testing.T) {
+	encoded := encodeDerivationPath([]uint32{0x80000000 | 44, 0x80000000 | 501})
+	want := []byte{2, 0x80, 0, 0, 44, 0x80, 0, 1, 0xf5}
+	if !bytes.Equal(encoded, want) {
+		t.Errorf("expected %x, got %x", want, encoded)
+	}
+}
+
+func TestBuildAPDU(t *testing.T) {
+	apdu, err := buildAPDU(claSolana, insGetPubkey, p1Confirm, 0x00, []byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("buildAPDU failed: %v", err)
+	}
+	want := []byte{claSolana, insGetPubkey, p1Confirm, 0x00, 3, 1, 2, 3}
+	if !bytes.Equal(apdu, want) {
+		t.Errorf("expected %x, got %x", want, apdu)
+	}
+}
+
+func TestBuildAPDURejectsOversizedData(t *testing.T) {
+	if _, err := buildAPDU(claSolana, insSignMessage, 0, 0, make([]byte, maxChunkSize+1)); err == nil {
+		t.Error("expected error for data exceeding maxChunkSize")
+	}
+}
+
+func TestChunkMessageWithPrefixSingleChunk(t *testing.T) {
+	chunks := chunkMessageWithPrefix(9, []byte("hello"))
+	if len(chunks) != 1 || string(chunks[0]) != "hello" {
+		t.Errorf("expected a single chunk \"hello\", got %v", chunks)
+	}
+}
+
+func TestChunkMessageWithPrefixMultipleChunks(t *testing.T) {
+	prefixLen := 9
+	message := make([]byte, maxChunkSize-prefixLen+10)
+	for i := range message {
+		message[i] = byte(i)
+	}
+
+	chunks := chunkMessageWithPrefix(prefixLen, message)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	if len(chunks[0]) != maxChunkSize-prefixLen {
+		t.Errorf("expected first chunk to fill the reserved capacity, got %d bytes", len(chunks[0]))
+	}
+
+	var reassembled []byte
+	reassembled = append(reassembled, chunks[0]...)
+	reassembled = append(reassembled, chunks[1]...)
+	if !bytes.Equal(reassembled, message) {
+		t.Error("expected chunks to reassemble into the original message")
+	}
+}
+
+func TestChunkMessageWithPrefixEmptyMessage(t *testing.T) {
+	chunks := chunkMessageWithPrefix(9, nil)
+	if len(chunks) != 1 || len(chunks[0]) != 0 {
+		t.Errorf("expected a single empty chunk, got %v", chunks)
+	}
+}
+
+func TestStatusWordErrorMessages(t *testing.T) {
+	cases := map[uint16]string{
+		0x6985: "rejected",
+		0x6982: "locked",
+		0x6e00: "not open",
+	}
+	for sw, substr := range cases {
+		err := &statusWordError{sw: sw}
+		if !bytes.Contains([]byte(err.Error()), []byte(substr)) {
+			t.Errorf("expected error for 0x%04x to mention %q, got %q", sw, substr, err.Error())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package ledger
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Ledger devices exchange APDUs over HID using a simple packet framing:
+// fixed-size reports tagged with a channel and sequence number, with the
+// total payload length prefixed onto the first packet. See Ledger's
+// hw-transport-node-hid implementation for the reference framing this
+// mirrors.
+const (
+	hidChannel    = 0x0101
+	hidTag        = 0x05
+	hidPacketSize = 64
+)
+
+// framePackets splits payload into hidPacketSize-byte HID reports framed
+// with the channel/tag/sequence header Ledger devices expect. The first
+// packet additionally carries payload's total length.
+func framePackets(payload []byte) [][]byte {
+	var packets [][]byte
+	seq := uint16(0)
+	offset := 0
+
+	for offset < len(payload) || seq == 0 {
+		packet := make([]byte, hidPacketSize)
+		binary.BigEndian.PutUint16(packet[0:2], hidChannel)
+		packet[2] = hidTag
+		binary.BigEndian.PutUint16(packet[3:5], seq)
+
+		pos := 5
+		if seq == 0 {
+			binary.BigEndian.PutUint16(packet[5:7], uint16(len(payload)))
+			pos = 7
+		}
+
+		n := copy(packet[pos:], payload[offset:])
+		offset += n
+		packets = append(packets, packet)
+		seq++
+
+		if offset >= len(payload) {
+			break
+		}
+	}
+
+	return packets
+}
+
+// deframePacket accumulates one HID report into an in-progress APDU
+// response. It returns the total payload once every expected byte has
+// arrived.
+type responseAssembler struct {
+	expected int
+	got      int
+	data     []byte
+	nextSeq  uint16
+}
+
+func (a *responseAssembler) addPacket(packet []byte) ([]byte, bool, error) {
+	if len(packet) < 5 {
+		return nil, false, fmt.Errorf("short HID packet: %d bytes", len(packet))
+	}
+	channel := binary.BigEndian.Uint16(packet[0:2])
+	tag := packet[2]
+	seq := binary.BigEndian.Uint16(packet[3:5])
+	if channel != hidChannel || tag != hidTag {
+		return nil, false, fmt.Errorf("unexpected HID channel/tag: %04x/%02x", channel, tag)
+	}
+	if seq != a.nextSeq {
+		return nil, false, fmt.Errorf("out-of-order HID packet: expected seq %d, got %d", a.nextSeq, seq)
+	}
+	a.nextSeq++
+
+	pos := 5
+	if seq == 0 {
+		if len(packet) < 7 {
+			return nil, false, fmt.Errorf("short initial HID packet: %d bytes", len(packet))
+		}
+		a.expected = int(binary.BigEndian.Uint16(packet[5:7]))
+		a.data = make([]byte, 0, a.expected)
+		pos = 7
+	}
+
+	remaining := a.expected - a.got
+	n := len(packet) - pos
+	if n > remaining {
+		n = remaining
+	}
+	if n > 0 {
+		a.data = append(a.data, packet[pos:pos+n]...)
+		a.got += n
+	}
+
+	if a.got >= a.expected {
+		return a.data, true, nil
+	}
+	return nil, false, nil
+}
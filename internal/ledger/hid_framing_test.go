@@ -0,0 +1,71 @@
+package ledger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFramePacketsSingleFrame(t *testing.T) {
+	payload := []byte("short apdu")
+	packets := framePackets(payload)
+	if len(packets) != 1 {
+		t.Fatalf("expected 1 packet, got %d", len(packets))
+	}
+	if len(packets[0]) != hidPacketSize {
+		t.Errorf("expected packet of %d bytes, got %d", hidPacketSize, len(packets[0]))
+	}
+}
+
+func TestFramePacketsMultiFrame(t *testing.T) {
+	payload := make([]byte, 200)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	packets := framePackets(payload)
+	if len(packets) < 2 {
+		t.Fatalf("expected more than 1 packet for a 200-byte payload, got %d", len(packets))
+	}
+	for _, p := range packets {
+		if len(p) != hidPacketSize {
+			t.Errorf("expected every packet to be %d bytes, got %d", hidPacketSize, len(p))
+		}
+	}
+}
+
+func TestFrameAndReassembleRoundTrip(t *testing.T) {
+	payload := make([]byte, 300)
+	for i := range payload {
+		payload[i] = byte(i * 3)
+	}
+
+	packets := framePackets(payload)
+
+	var assembler responseAssembler
+	var got []byte
+	for _, p := range packets {
+		data, done, err := assembler.addPacket(p)
+		if err != nil {
+			t.Fatalf("addPacket failed: %v", err)
+		}
+		if done {
+			got = data
+		}
+	}
+
+	if !bytes.Equal(got, payload) {
+		t.Errorf("expected reassembled payload to match original")
+	}
+}
+
+func TestResponseAssemblerRejectsOutOfOrder(t *testing.T) {
+	payload := make([]byte, 300)
+	packets := framePackets(payload)
+	if len(packets) < 2 {
+		t.Fatalf("expected multiple packets for a 300-byte payload")
+	}
+
+	var assembler responseAssembler
+	if _, _, err := assembler.addPacket(packets[1]); err == nil {
+		t.Error("expected error when packets arrive out of order")
+	}
+}
@@ -0,0 +1,144 @@
+package ledger
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/karalabe/hid"
+)
+
+// ledgerVendorID is Ledger's registered USB vendor ID, shared by every
+// Nano/Stax model.
+const ledgerVendorID = 0x2c97
+
+// Device is an open HID connection to a Ledger device running the Solana
+// app.
+type Device struct {
+	hidDevice *hid.Device
+}
+
+// Open connects to the first Ledger device found over HID. Callers must
+// Close the returned Device when done.
+func Open() (*Device, error) {
+	infos := hid.Enumerate(ledgerVendorID, 0)
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("no Ledger device found; is it connected and unlocked?")
+	}
+
+	hidDevice, err := infos[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Ledger device: %w", err)
+	}
+
+	return &Device{hidDevice: hidDevice}, nil
+}
+
+// Close releases the underlying HID connection.
+func (d *Device) Close() error {
+	return d.hidDevice.Close()
+}
+
+// exchange sends one APDU to the device and returns its response data,
+// returning an error if the device's status word does not indicate success
+// (0x9000).
+func (d *Device) exchange(apdu []byte) ([]byte, error) {
+	for _, packet := range framePackets(apdu) {
+		if _, err := d.hidDevice.Write(packet); err != nil {
+			return nil, fmt.Errorf("failed to write to Ledger device: %w", err)
+		}
+	}
+
+	var assembler responseAssembler
+	var response []byte
+	for {
+		packet := make([]byte, hidPacketSize)
+		if _, err := d.hidDevice.Read(packet); err != nil {
+			return nil, fmt.Errorf("failed to read from Ledger device: %w", err)
+		}
+		data, done, err := assembler.addPacket(packet)
+		if err != nil {
+			return nil, fmt.Errorf("malformed response from Ledger device: %w", err)
+		}
+		if done {
+			response = data
+			break
+		}
+	}
+
+	if len(response) < 2 {
+		return nil, fmt.Errorf("response from Ledger device too short: %d bytes", len(response))
+	}
+
+	sw := uint16(response[len(response)-2])<<8 | uint16(response[len(response)-1])
+	payload := response[:len(response)-2]
+	if sw != 0x9000 {
+		return nil, &statusWordError{sw: sw}
+	}
+	return payload, nil
+}
+
+// GetPublicKey retrieves the Ed25519 public key for path from the device's
+// Solana app. If confirm is set, the device displays the derived address
+// and requires physical approval before responding.
+func (d *Device) GetPublicKey(path []uint32, confirm bool) (ed25519.PublicKey, error) {
+	p1 := byte(p1NoConfirm)
+	if confirm {
+		p1 = p1Confirm
+	}
+
+	apdu, err := buildAPDU(claSolana, insGetPubkey, p1, 0x00, encodeDerivationPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := d.exchange(apdu)
+	if err != nil {
+		return nil, fmt.Errorf("GET_PUBKEY failed: %w", err)
+	}
+	if len(payload) < ed25519.PublicKeySize {
+		return nil, fmt.Errorf("GET_PUBKEY returned %d bytes, expected at least %d", len(payload), ed25519.PublicKeySize)
+	}
+
+	return ed25519.PublicKey(payload[:ed25519.PublicKeySize]), nil
+}
+
+// SignMessage asks the device's Solana app to sign message with the key at
+// path, chunking message across multiple SIGN_MESSAGE APDUs when it
+// exceeds a single frame's capacity. It returns the raw 64-byte Ed25519
+// signature.
+func (d *Device) SignMessage(path []uint32, message []byte) ([64]byte, error) {
+	var signature [64]byte
+
+	pathBytes := encodeDerivationPath(path)
+	chunks := chunkMessageWithPrefix(len(pathBytes), message)
+
+	for i, chunk := range chunks {
+		p1 := byte(p1MoreChunks)
+		if i == len(chunks)-1 {
+			p1 = p1LastChunk
+		}
+
+		data := chunk
+		if i == 0 {
+			data = append(append([]byte(nil), pathBytes...), chunk...)
+		}
+
+		apdu, err := buildAPDU(claSolana, insSignMessage, p1, 0x00, data)
+		if err != nil {
+			return signature, fmt.Errorf("failed to build SIGN_MESSAGE chunk %d: %w", i, err)
+		}
+
+		payload, err := d.exchange(apdu)
+		if err != nil {
+			return signature, fmt.Errorf("SIGN_MESSAGE failed: %w", err)
+		}
+		if i == len(chunks)-1 {
+			if len(payload) != 64 {
+				return signature, fmt.Errorf("SIGN_MESSAGE returned %d bytes, expected 64", len(payload))
+			}
+			copy(signature[:], payload)
+		}
+	}
+
+	return signature, nil
+}
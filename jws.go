@@ -0,0 +1,286 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// jwsAlg and jwsCrv are the JOSE algorithm/curve identifiers this tool emits
+// and accepts, per RFC 8037 (CFRG elliptic curve signatures in JOSE).
+const (
+	jwsAlg = "EdDSA"
+	jwsCrv = "Ed25519"
+)
+
+// jwsHeaderFlags collects repeated `-jws-header key=value` flags into a map,
+// implementing flag.Value so callers can pass the flag multiple times.
+type jwsHeaderFlags map[string]string
+
+func (h jwsHeaderFlags) String() string {
+	if h == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(h))
+	for k, v := range h {
+		parts = append(parts, k+"="+v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (h jwsHeaderFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected -jws-header in key=value form, got %q", value)
+	}
+	h[key] = val
+	return nil
+}
+
+// jwsJWK is the minimal JSON Web Key representation for an Ed25519 public
+// key, per RFC 8037.
+type jwsJWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// jwsFlattenedOrGeneralSignature is one signature entry in the JSON general
+// serialization, and also the shape of the single signature embedded
+// directly in the flattened serialization.
+type jwsSignatureEntry struct {
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+// jwsGeneralJSON is the RFC 7515 section 7.2.1 general JSON serialization.
+type jwsGeneralJSON struct {
+	Payload    string              `json:"payload"`
+	Signatures []jwsSignatureEntry `json:"signatures"`
+}
+
+// jwsFlattenedJSON is the RFC 7515 section 7.2.2 flattened JSON serialization.
+type jwsFlattenedJSON struct {
+	Payload   string `json:"payload"`
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+// base64URLEncode encodes to unpadded base64url, as required throughout JWS.
+func base64URLEncode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// base64URLDecode decodes unpadded (or padded) base64url.
+func base64URLDecode(s string) ([]byte, error) {
+	if m := len(s) % 4; m != 0 {
+		s += strings.Repeat("=", 4-m)
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
+
+// buildJWSProtectedHeader constructs the protected header for an Ed25519
+// JWS: alg/typ plus any user-supplied entries, and a kid derived from the
+// base58-encoded public key so verifiers can identify the signer.
+func buildJWSProtectedHeader(pub ed25519.PublicKey, extra map[string]string) (string, error) {
+	header := map[string]string{
+		"alg": jwsAlg,
+		"typ": "JWT",
+	}
+	for k, v := range extra {
+		header[k] = v
+	}
+	if _, ok := header["kid"]; !ok {
+		header["kid"] = base58Encode(pub)
+	}
+
+	raw, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal protected header: %w", err)
+	}
+	return base64URLEncode(raw), nil
+}
+
+// signJWS wraps payload in an RFC 7515 JSON Web Signature using alg EdDSA /
+// crv Ed25519, returning the requested serialization ("compact", "json", or
+// "flattened").
+func signJWS(keypair ed25519.PrivateKey, payload []byte, serialization string, extraHeaders map[string]string) (string, error) {
+	pub := keypair.Public().(ed25519.PublicKey)
+
+	encodedHeader, err := buildJWSProtectedHeader(pub, extraHeaders)
+	if err != nil {
+		return "", err
+	}
+	encodedPayload := base64URLEncode(payload)
+
+	signingInput := encodedHeader + "." + encodedPayload
+	signature := ed25519.Sign(keypair, []byte(signingInput))
+	encodedSignature := base64URLEncode(signature)
+
+	switch serialization {
+	case "", "compact":
+		return signingInput + "." + encodedSignature, nil
+	case "json":
+		doc := jwsGeneralJSON{
+			Payload: encodedPayload,
+			Signatures: []jwsSignatureEntry{
+				{Protected: encodedHeader, Signature: encodedSignature},
+			},
+		}
+		out, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal JWS JSON serialization: %w", err)
+		}
+		return string(out), nil
+	case "flattened":
+		doc := jwsFlattenedJSON{
+			Payload:   encodedPayload,
+			Protected: encodedHeader,
+			Signature: encodedSignature,
+		}
+		out, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal JWS flattened serialization: %w", err)
+		}
+		return string(out), nil
+	default:
+		return "", fmt.Errorf("unknown JWS serialization: %s (expected compact, json, or flattened)", serialization)
+	}
+}
+
+// jwsProtectedHeader is the subset of protected header fields verifyJWS
+// understands in order to resolve an embedded JWK when no public key is
+// supplied by the caller.
+type jwsProtectedHeader struct {
+	Alg string  `json:"alg"`
+	JWK *jwsJWK `json:"jwk,omitempty"`
+}
+
+// verifyJWS parses a compact, general JSON, or flattened JWS and validates
+// its signature with ed25519.Verify. If pub is nil, the public key is taken
+// from an embedded "jwk" field in the protected header instead.
+func verifyJWS(serialized string, pub ed25519.PublicKey) ([]byte, error) {
+	trimmed := strings.TrimSpace(serialized)
+
+	var encodedHeader, encodedPayload, encodedSignature string
+
+	if strings.HasPrefix(trimmed, "{") {
+		var flattened jwsFlattenedJSON
+		if err := json.Unmarshal([]byte(trimmed), &flattened); err == nil && flattened.Signature != "" {
+			encodedHeader = flattened.Protected
+			encodedPayload = flattened.Payload
+			encodedSignature = flattened.Signature
+		} else {
+			var general jwsGeneralJSON
+			if err := json.Unmarshal([]byte(trimmed), &general); err != nil {
+				return nil, fmt.Errorf("failed to parse JWS JSON serialization: %w", err)
+			}
+			if len(general.Signatures) == 0 {
+				return nil, fmt.Errorf("JWS JSON serialization has no signatures")
+			}
+			encodedHeader = general.Signatures[0].Protected
+			encodedPayload = general.Payload
+			encodedSignature = general.Signatures[0].Signature
+		}
+	} else {
+		parts := strings.Split(trimmed, ".")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("compact JWS must have 3 dot-separated parts, got %d", len(parts))
+		}
+		encodedHeader, encodedPayload, encodedSignature = parts[0], parts[1], parts[2]
+	}
+
+	if pub == nil {
+		headerJSON, err := base64URLDecode(encodedHeader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode protected header: %w", err)
+		}
+		var header jwsProtectedHeader
+		if err := json.Unmarshal(headerJSON, &header); err != nil {
+			return nil, fmt.Errorf("failed to parse protected header: %w", err)
+		}
+		if header.JWK == nil {
+			return nil, fmt.Errorf("no public key provided and protected header has no embedded jwk")
+		}
+		x, err := base64URLDecode(header.JWK.X)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode embedded jwk x coordinate: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid embedded jwk public key length: expected %d bytes, got %d", ed25519.PublicKeySize, len(x))
+		}
+		pub = ed25519.PublicKey(x)
+	}
+
+	signingInput := encodedHeader + "." + encodedPayload
+	signature, err := base64URLDecode(encodedSignature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid signature length: expected %d bytes, got %d", ed25519.SignatureSize, len(signature))
+	}
+
+	if !ed25519.Verify(pub, []byte(signingInput), signature) {
+		return nil, fmt.Errorf("signature verification failed")
+	}
+
+	payload, err := base64URLDecode(encodedPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	return payload, nil
+}
+
+// runVerifyJWSCommand implements the `verify-jws` subcommand: it parses a
+// JWS (compact, general JSON, or flattened) and validates its signature,
+// printing the decoded payload on success.
+func runVerifyJWSCommand(args []string) {
+	fs := flag.NewFlagSet("verify-jws", flag.ExitOnError)
+	jws := fs.String("jws", "", "JWS to verify (compact, JSON general, or flattened serialization)")
+	jwsFile := fs.String("jws-file", "", "Path to file containing the JWS to verify")
+	publicKey := fs.String("public-key", "", "Base58-encoded Ed25519 public key (omit to use an embedded JWK)")
+	fs.Parse(args)
+
+	var serialized string
+	switch {
+	case *jws != "" && *jwsFile != "":
+		log.Fatalf("Error: Cannot use both -jws and -jws-file at the same time")
+	case *jws != "":
+		serialized = *jws
+	case *jwsFile != "":
+		data, err := os.ReadFile(*jwsFile)
+		if err != nil {
+			log.Fatalf("Failed to read JWS file: %v", err)
+		}
+		serialized = string(data)
+	default:
+		log.Fatalf("Error: Either -jws or -jws-file must be provided")
+	}
+
+	var pub ed25519.PublicKey
+	if *publicKey != "" {
+		decoded, err := base58Decode(*publicKey)
+		if err != nil {
+			log.Fatalf("Failed to decode public key: %v", err)
+		}
+		if len(decoded) != ed25519.PublicKeySize {
+			log.Fatalf("Invalid public key length: expected %d bytes, got %d", ed25519.PublicKeySize, len(decoded))
+		}
+		pub = ed25519.PublicKey(decoded)
+	}
+
+	payload, err := verifyJWS(serialized, pub)
+	if err != nil {
+		log.Fatalf("JWS verification failed: %v", err)
+	}
+
+	fmt.Println("JWS signature is valid")
+	fmt.Printf("Payload: %s\n", payload)
+}
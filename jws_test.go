@@ -0,0 +1,167 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testJWSKeypair(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate test keypair: %v", err)
+	}
+	return priv
+}
+
+func TestSignAndVerifyJWSCompact(t *testing.T) {
+	priv := testJWSKeypair(t)
+	jws, err := signJWS(priv, []byte("hello world"), "compact", nil)
+	if err != nil {
+		t.Fatalf("signJWS failed: %v", err)
+	}
+	if strings.Count(jws, ".") != 2 {
+		t.Fatalf("expected compact JWS to have 2 dots, got %q", jws)
+	}
+
+	payload, err := verifyJWS(jws, priv.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatalf("verifyJWS failed: %v", err)
+	}
+	if string(payload) != "hello world" {
+		t.Errorf("expected payload %q, got %q", "hello world", payload)
+	}
+}
+
+func TestSignAndVerifyJWSJSON(t *testing.T) {
+	priv := testJWSKeypair(t)
+	jws, err := signJWS(priv, []byte("json payload"), "json", nil)
+	if err != nil {
+		t.Fatalf("signJWS failed: %v", err)
+	}
+
+	var doc jwsGeneralJSON
+	if err := json.Unmarshal([]byte(jws), &doc); err != nil {
+		t.Fatalf("expected valid JSON general serialization: %v", err)
+	}
+	if len(doc.Signatures) != 1 {
+		t.Fatalf("expected exactly one signature, got %d", len(doc.Signatures))
+	}
+
+	payload, err := verifyJWS(jws, priv.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatalf("verifyJWS failed: %v", err)
+	}
+	if string(payload) != "json payload" {
+		t.Errorf("expected payload %q, got %q", "json payload", payload)
+	}
+}
+
+func TestSignAndVerifyJWSFlattened(t *testing.T) {
+	priv := testJWSKeypair(t)
+	jws, err := signJWS(priv, []byte("flattened payload"), "flattened", nil)
+	if err != nil {
+		t.Fatalf("signJWS failed: %v", err)
+	}
+
+	payload, err := verifyJWS(jws, priv.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatalf("verifyJWS failed: %v", err)
+	}
+	if string(payload) != "flattened payload" {
+		t.Errorf("expected payload %q, got %q", "flattened payload", payload)
+	}
+}
+
+func TestSignJWSUnknownSerialization(t *testing.T) {
+	priv := testJWSKeypair(t)
+	if _, err := signJWS(priv, []byte("x"), "bogus", nil); err == nil {
+		t.Error("expected error for unknown serialization")
+	}
+}
+
+func TestSignJWSCustomHeader(t *testing.T) {
+	priv := testJWSKeypair(t)
+	jws, err := signJWS(priv, []byte("x"), "compact", map[string]string{"cty": "text/plain"})
+	if err != nil {
+		t.Fatalf("signJWS failed: %v", err)
+	}
+
+	headerJSON, err := base64URLDecode(strings.Split(jws, ".")[0])
+	if err != nil {
+		t.Fatalf("failed to decode header: %v", err)
+	}
+	var header map[string]string
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		t.Fatalf("failed to parse header: %v", err)
+	}
+	if header["cty"] != "text/plain" {
+		t.Errorf("expected cty header to be preserved, got %q", header["cty"])
+	}
+	if header["alg"] != jwsAlg {
+		t.Errorf("expected alg %q, got %q", jwsAlg, header["alg"])
+	}
+}
+
+func TestVerifyJWSTamperedSignature(t *testing.T) {
+	priv := testJWSKeypair(t)
+	jws, err := signJWS(priv, []byte("hello world"), "compact", nil)
+	if err != nil {
+		t.Fatalf("signJWS failed: %v", err)
+	}
+
+	parts := strings.Split(jws, ".")
+	tampered := parts[0] + "." + parts[1] + "." + strings.Repeat("A", len(parts[2]))
+
+	if _, err := verifyJWS(tampered, priv.Public().(ed25519.PublicKey)); err == nil {
+		t.Error("expected verification failure for tampered signature")
+	}
+}
+
+func TestVerifyJWSEmbeddedJWK(t *testing.T) {
+	priv := testJWSKeypair(t)
+	pub := priv.Public().(ed25519.PublicKey)
+
+	header := map[string]interface{}{
+		"alg": jwsAlg,
+		"typ": "JWT",
+		"jwk": jwsJWK{Kty: "OKP", Crv: jwsCrv, X: base64URLEncode(pub)},
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("failed to marshal header: %v", err)
+	}
+	encodedHeader := base64URLEncode(headerJSON)
+	encodedPayload := base64URLEncode([]byte("embedded jwk payload"))
+	signature := ed25519.Sign(priv, []byte(encodedHeader+"."+encodedPayload))
+	jws := encodedHeader + "." + encodedPayload + "." + base64URLEncode(signature)
+
+	payload, err := verifyJWS(jws, nil)
+	if err != nil {
+		t.Fatalf("verifyJWS with embedded jwk failed: %v", err)
+	}
+	if string(payload) != "embedded jwk payload" {
+		t.Errorf("expected payload %q, got %q", "embedded jwk payload", payload)
+	}
+}
+
+func TestVerifyJWSInvalidCompactShape(t *testing.T) {
+	if _, err := verifyJWS("not.a.valid.jws", nil); err == nil {
+		t.Error("expected error for malformed compact JWS")
+	}
+}
+
+func TestJWSHeaderFlagsSet(t *testing.T) {
+	h := make(jwsHeaderFlags)
+	if err := h.Set("cty=text/plain"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h["cty"] != "text/plain" {
+		t.Errorf("expected cty=text/plain, got %q", h["cty"])
+	}
+	if err := h.Set("no-equals-sign"); err == nil {
+		t.Error("expected error for malformed -jws-header value")
+	}
+}
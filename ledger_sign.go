@@ -0,0 +1,46 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/Aryamanraj/go-sol-sign/internal/ledger"
+)
+
+// signWithLedger signs message with the key at derivationPath on a
+// connected Ledger device running the Solana app. If confirm is set, the
+// derived public key is fetched (with on-device display) and printed
+// before the device is asked to sign, so the caller can visually confirm
+// the signing address.
+func signWithLedger(derivationPath string, confirm bool, message []byte) (ed25519.PublicKey, [64]byte, error) {
+	var signature [64]byte
+
+	indices, err := parseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, signature, fmt.Errorf("invalid ledger derivation path: %w", err)
+	}
+
+	dev, err := ledger.Open()
+	if err != nil {
+		return nil, signature, err
+	}
+	defer dev.Close()
+
+	pub, err := dev.GetPublicKey(indices, confirm)
+	if err != nil {
+		return nil, signature, fmt.Errorf("failed to get public key from Ledger device: %w", err)
+	}
+
+	if confirm {
+		fmt.Fprintf(os.Stderr, "Ledger public key: %s\n", base58Encode(pub))
+		fmt.Fprintf(os.Stderr, "Approve the signing request on your device...\n")
+	}
+
+	signature, err = dev.SignMessage(indices, message)
+	if err != nil {
+		return nil, signature, fmt.Errorf("failed to sign with Ledger device: %w", err)
+	}
+
+	return pub, signature, nil
+}
@@ -4,12 +4,14 @@
 // sign messages using Ed25519 private keys in the standard Solana keypair format.
 //
 // Usage:
-//   go-sol-sign -keypair <path> -message <message> [-format base58|base64|hex]
+//
+//	go-sol-sign -keypair <path> -message <message> [-format base58|base64|hex]
 //
 // Examples:
-//   go-sol-sign -keypair ~/.config/solana/id.json -message "Hello World"
-//   go-sol-sign -keypair ./keypair.json -message "Test" -format hex
-//   go-sol-sign -private-key "base58key" -message "Test" -format base58
+//
+//	go-sol-sign -keypair ~/.config/solana/id.json -message "Hello World"
+//	go-sol-sign -keypair ./keypair.json -message "Test" -format hex
+//	go-sol-sign -private-key "base58key" -message "Test" -format base58
 package main
 
 import (
@@ -29,22 +31,49 @@ import (
 const (
 	// Version of the go-sol-sign tool
 	Version = "1.2.0"
-	
+
 	// Tool name and description
 	ToolName        = "go-sol-sign"
 	ToolDescription = "Sign messages with Solana keypairs"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "verify-jws" {
+		runVerifyJWSCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "verify-offchain" {
+		runVerifyOffchainCommand(os.Args[2:])
+		return
+	}
+
 	var (
-		keypairPath  = flag.String("keypair", "", "Path to Solana keypair JSON file")
-		privateKey   = flag.String("private-key", "", "Private key as base58 string (alternative to -keypair)")
-		message      = flag.String("message", "", "Message to sign")
-		messageFile  = flag.String("message-file", "", "Path to file containing message to sign")
-		outputFormat = flag.String("format", "base58", "Output format: base58, base64, hex")
-		version      = flag.Bool("version", false, "Show version information")
-		verbose      = flag.Bool("verbose", false, "Enable verbose output")
+		keypairPath          = flag.String("keypair", "", "Path to Solana keypair JSON file")
+		privateKey           = flag.String("private-key", "", "Private key as base58 string (alternative to -keypair)")
+		mnemonic             = flag.String("mnemonic", "", "BIP-39 mnemonic seed phrase (alternative to -keypair/-private-key)")
+		passphrase           = flag.String("passphrase", "", "Optional BIP-39 passphrase used with -mnemonic")
+		derivationPath       = flag.String("derivation-path", defaultSolanaDerivationPath, "BIP-44 derivation path used with -mnemonic")
+		message              = flag.String("message", "", "Message to sign")
+		messageFile          = flag.String("message-file", "", "Path to file containing message to sign")
+		outputFormat         = flag.String("format", "base58", "Output format: base58, base64, hex, jws")
+		jwsSerialization     = flag.String("jws-serialization", "compact", "JWS serialization when -format jws: compact, json, flattened")
+		mode                 = flag.String("mode", "message", "Signing mode: message, transaction, offchain")
+		offchainDomain       = flag.String("offchain-domain", "", "Hex-encoded 32-byte application domain identifier (used with -mode offchain, default: all zeros)")
+		tx                   = flag.String("tx", "", "Base58- or base64-encoded unsigned Solana transaction (used with -mode transaction)")
+		txFile               = flag.String("tx-file", "", "Path to file containing the transaction to sign (used with -mode transaction)")
+		allowUnsafe          = flag.Bool("allow-unsafe", false, "Sign a transaction even if the keypair isn't a required signer (used with -mode transaction)")
+		dump                 = flag.Bool("dump", false, "Pretty-print the decoded transaction message instead of signing (used with -mode transaction)")
+		passphraseStdin      = flag.Bool("passphrase-stdin", false, "Read the keypair file passphrase from stdin (used with an encrypted -keypair)")
+		passphraseEnv        = flag.String("passphrase-env", "", "Name of an environment variable holding the keypair file passphrase")
+		encryptKeypairOut    = flag.String("encrypt-keypair-out", "", "Write -keypair re-encrypted with the given passphrase to this path, then exit")
+		useLedger            = flag.Bool("ledger", false, "Sign with a connected Ledger device instead of a local keypair (alternative to -keypair/-private-key/-mnemonic)")
+		ledgerDerivationPath = flag.String("ledger-derivation-path", defaultSolanaDerivationPath, "BIP-44 derivation path used with -ledger")
+		confirmOnDevice      = flag.Bool("confirm", false, "Display and confirm the Ledger-derived public key before signing (used with -ledger)")
+		version              = flag.Bool("version", false, "Show version information")
+		verbose              = flag.Bool("verbose", false, "Enable verbose output")
 	)
+	jwsHeaders := make(jwsHeaderFlags)
+	flag.Var(jwsHeaders, "jws-header", "Extra protected header entry as key=value when -format jws (repeatable)")
 	flag.Parse()
 
 	// Handle version flag
@@ -55,19 +84,56 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Validate required arguments
-	if (*keypairPath == "" && *privateKey == "") {
-		fmt.Fprintf(os.Stderr, "Error: Either -keypair or -private-key must be provided\n\n")
+	// Validate required arguments. -mode transaction -dump only inspects the
+	// transaction and never touches a keypair, so it's exempt.
+	dumpingTransaction := *mode == "transaction" && *dump
+	if !dumpingTransaction && *keypairPath == "" && *privateKey == "" && *mnemonic == "" && !*useLedger {
+		fmt.Fprintf(os.Stderr, "Error: One of -keypair, -private-key, -mnemonic or -ledger must be provided\n\n")
 		printUsage()
 		os.Exit(1)
 	}
 
-	if *keypairPath != "" && *privateKey != "" {
-		fmt.Fprintf(os.Stderr, "Error: Cannot use both -keypair and -private-key at the same time\n\n")
+	keySourceCount := 0
+	for _, provided := range []bool{*keypairPath != "", *privateKey != "", *mnemonic != "", *useLedger} {
+		if provided {
+			keySourceCount++
+		}
+	}
+	if keySourceCount > 1 {
+		fmt.Fprintf(os.Stderr, "Error: Only one of -keypair, -private-key, -mnemonic or -ledger may be used at a time\n\n")
 		printUsage()
 		os.Exit(1)
 	}
 
+	keypairPassphrase, err := resolveKeypairPassphrase(*passphraseStdin, *passphraseEnv)
+	if err != nil {
+		log.Fatalf("Failed to resolve keypair passphrase: %v", err)
+	}
+
+	if *encryptKeypairOut != "" {
+		runEncryptKeypairOutCommand(*keypairPath, keypairPassphrase, *encryptKeypairOut)
+		return
+	}
+
+	switch *mode {
+	case "transaction":
+		if *useLedger {
+			log.Fatalf("Error: -ledger is not supported with -mode transaction")
+		}
+		runTransactionMode(*keypairPath, *privateKey, *mnemonic, *passphrase, *derivationPath, keypairPassphrase, *tx, *txFile, *outputFormat, *allowUnsafe, *dump, *verbose)
+		return
+	case "offchain":
+		if *useLedger {
+			log.Fatalf("Error: -ledger is not supported with -mode offchain")
+		}
+		runOffchainMode(*keypairPath, *privateKey, *mnemonic, *passphrase, *derivationPath, keypairPassphrase, *message, *messageFile, *offchainDomain, *outputFormat, *verbose)
+		return
+	case "message":
+		// handled below
+	default:
+		log.Fatalf("Unknown mode: %s. Supported modes: message, transaction, offchain", *mode)
+	}
+
 	// Get message from either flag or file
 	var messageText string
 	if *message != "" && *messageFile != "" {
@@ -90,23 +156,20 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Expand home directory if present for keypair path
-	if *keypairPath != "" {
-		if !filepath.IsAbs(*keypairPath) && (*keypairPath)[0] == '~' {
-			homeDir, err := os.UserHomeDir()
-			if err == nil {
-				*keypairPath = filepath.Join(homeDir, (*keypairPath)[1:])
-			}
-		}
-	}
+	*keypairPath = expandHomeDir(*keypairPath)
 
 	if *verbose {
-		if *keypairPath != "" {
+		switch {
+		case *keypairPath != "":
 			fmt.Fprintf(os.Stderr, "Loading keypair from: %s\n", *keypairPath)
-		} else {
+		case *mnemonic != "":
+			fmt.Fprintf(os.Stderr, "Deriving keypair from mnemonic using path: %s\n", *derivationPath)
+		case *useLedger:
+			fmt.Fprintf(os.Stderr, "Signing with Ledger device using path: %s\n", *ledgerDerivationPath)
+		default:
 			fmt.Fprintf(os.Stderr, "Using provided private key\n")
 		}
-		
+
 		// Show message info (truncated if very long)
 		if len(messageText) > 100 {
 			fmt.Fprintf(os.Stderr, "Message to sign: %s... (%d total chars)\n", messageText[:100], len(messageText))
@@ -116,16 +179,36 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Output format: %s\n", *outputFormat)
 	}
 
-	// Load the keypair
-	var keypair ed25519.PrivateKey
-	var err error
-	
-	if *keypairPath != "" {
-		keypair, err = loadKeypairFromFile(*keypairPath)
-	} else {
-		keypair, err = loadKeypairFromString(*privateKey)
+	if *useLedger {
+		if *outputFormat == "jws" {
+			log.Fatalf("Error: -format jws is not supported with -ledger")
+		}
+
+		_, signature, err := signWithLedger(*ledgerDerivationPath, *confirmOnDevice, []byte(messageText))
+		if err != nil {
+			log.Fatalf("Failed to sign with Ledger device: %v", err)
+		}
+
+		if *verbose {
+			fmt.Fprintf(os.Stderr, "Message signed successfully\n")
+			fmt.Fprintf(os.Stderr, "Signature length: %d bytes\n", len(signature))
+		}
+
+		switch *outputFormat {
+		case "base64":
+			fmt.Println(base64.StdEncoding.EncodeToString(signature[:]))
+		case "hex":
+			fmt.Println(hex.EncodeToString(signature[:]))
+		case "base58":
+			fmt.Println(base58Encode(signature[:]))
+		default:
+			log.Fatalf("Unknown format: %s. Supported formats with -ledger: base58, base64, hex", *outputFormat)
+		}
+		return
 	}
-	
+
+	// Load the keypair
+	keypair, err := loadSelectedKeypair(*keypairPath, *privateKey, *mnemonic, *passphrase, *derivationPath, keypairPassphrase)
 	if err != nil {
 		log.Fatalf("Failed to load keypair: %v", err)
 	}
@@ -151,8 +234,14 @@ func main() {
 		fmt.Println(hex.EncodeToString(signature))
 	case "base58":
 		fmt.Println(base58Encode(signature))
+	case "jws":
+		jws, err := signJWS(keypair, []byte(messageText), *jwsSerialization, jwsHeaders)
+		if err != nil {
+			log.Fatalf("Failed to build JWS: %v", err)
+		}
+		fmt.Println(jws)
 	default:
-		log.Fatalf("Unknown format: %s. Supported formats: base58, base64, hex", *outputFormat)
+		log.Fatalf("Unknown format: %s. Supported formats: base58, base64, hex, jws", *outputFormat)
 	}
 }
 
@@ -175,28 +264,80 @@ func printUsage() {
 	fmt.Println("Usage:")
 	fmt.Printf("  %s [options]\n\n", ToolName)
 	fmt.Println("Key Options (choose one):")
-	fmt.Println("  -keypair string      Path to Solana keypair JSON file")
-	fmt.Println("  -private-key string  Private key as base58 string")
+	fmt.Println("  -keypair string         Path to Solana keypair JSON file")
+	fmt.Println("  -private-key string     Private key as base58 string")
+	fmt.Println("  -mnemonic string        BIP-39 mnemonic seed phrase")
+	fmt.Println("  -passphrase string      Optional BIP-39 passphrase (used with -mnemonic)")
+	fmt.Println("  -derivation-path string BIP-44 derivation path (used with -mnemonic, default: m/44'/501'/0'/0')")
+	fmt.Println("  -ledger                 Sign with a connected Ledger device instead of a local keypair")
+	fmt.Println("  -ledger-derivation-path string  BIP-44 derivation path used with -ledger (default: m/44'/501'/0'/0')")
+	fmt.Println("  -confirm                Display and confirm the Ledger-derived public key before signing (used with -ledger)")
 	fmt.Println("")
 	fmt.Println("Message Options (choose one):")
 	fmt.Println("  -message string      Message to sign")
 	fmt.Println("  -message-file string Path to file containing message")
 	fmt.Println("")
 	fmt.Println("Other Options:")
-	fmt.Println("  -format string       Output format: base58, base64, hex (default: base58)")
-	fmt.Println("  -verbose             Enable verbose output")
-	fmt.Println("  -version             Show version information")
+	fmt.Println("  -format string            Output format: base58, base64, hex, jws (default: base58)")
+	fmt.Println("  -jws-serialization string JWS serialization when -format jws: compact, json, flattened (default: compact)")
+	fmt.Println("  -jws-header key=value     Extra JWS protected header entry (used with -format jws, repeatable)")
+	fmt.Println("  -mode string              Signing mode: message, transaction, offchain (default: message)")
+	fmt.Println("  -tx string                Unsigned Solana transaction to sign (used with -mode transaction)")
+	fmt.Println("  -tx-file string           Path to file containing the transaction (used with -mode transaction)")
+	fmt.Println("  -allow-unsafe             Sign even if the keypair isn't a required signer (used with -mode transaction)")
+	fmt.Println("  -dump                     Pretty-print the decoded message instead of signing (used with -mode transaction)")
+	fmt.Println("  -offchain-domain string   Hex-encoded 32-byte application domain identifier (used with -mode offchain, default: all zeros)")
+	fmt.Println("  -passphrase-stdin         Read the keypair file passphrase from stdin (used with an encrypted -keypair)")
+	fmt.Println("  -passphrase-env string    Name of an environment variable holding the keypair file passphrase")
+	fmt.Println("  -encrypt-keypair-out string  Write -keypair re-encrypted with the given passphrase to this path, then exit")
+	fmt.Println("  -verbose                  Enable verbose output")
+	fmt.Println("  -version                  Show version information")
+	fmt.Println("")
+	fmt.Println("Subcommands:")
+	fmt.Println("  verify-jws                Verify a JWS produced by -format jws (see verify-jws -h)")
+	fmt.Println("  verify-offchain           Verify a signature produced by -mode offchain (see verify-offchain -h)")
 	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Printf("  %s -keypair ~/.config/solana/id.json -message \"Hello World\"\n", ToolName)
 	fmt.Printf("  %s -private-key 3yD2... -message \"Test\" -format hex\n", ToolName)
 	fmt.Printf("  %s -keypair ./keypair.json -message-file ./message.txt\n", ToolName)
 	fmt.Printf("  %s -private-key 3yD2... -message \"Test\" -format base58\n", ToolName)
+	fmt.Printf("  %s -keypair ./keypair.json -message \"Test\" -format jws\n", ToolName)
 	fmt.Printf("  %s -version\n", ToolName)
 }
 
-// loadKeypairFromFile loads and validates a Solana keypair from a JSON file
-func loadKeypairFromFile(path string) (ed25519.PrivateKey, error) {
+// expandHomeDir expands a leading "~" in path to the current user's home
+// directory, leaving absolute and already-relative paths untouched.
+func expandHomeDir(path string) string {
+	if path == "" || filepath.IsAbs(path) || path[0] != '~' {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
+
+// loadSelectedKeypair loads a keypair from whichever of -keypair,
+// -private-key, or -mnemonic was provided. keypairPassphrase decrypts an
+// encrypted -keypair file; mnemonicPassphrase is the BIP-39 passphrase
+// used with -mnemonic.
+func loadSelectedKeypair(keypairPath, privateKey, mnemonic, mnemonicPassphrase, derivationPath, keypairPassphrase string) (ed25519.PrivateKey, error) {
+	switch {
+	case keypairPath != "":
+		return loadKeypairFromFile(expandHomeDir(keypairPath), keypairPassphrase)
+	case mnemonic != "":
+		return loadKeypairFromMnemonic(mnemonic, mnemonicPassphrase, derivationPath)
+	default:
+		return loadKeypairFromString(privateKey)
+	}
+}
+
+// loadKeypairFromFile loads and validates a Solana keypair from a JSON
+// file. If the file holds an encrypted keypair (a JSON object rather than
+// a plain array of bytes), it is decrypted with passphrase first.
+func loadKeypairFromFile(path, passphrase string) (ed25519.PrivateKey, error) {
 	// Check if file exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil, fmt.Errorf("keypair file does not exist: %s", path)
@@ -208,6 +349,10 @@ func loadKeypairFromFile(path string) (ed25519.PrivateKey, error) {
 		return nil, fmt.Errorf("failed to read keypair file: %w", err)
 	}
 
+	if looksLikeEncryptedKeypair(data) {
+		return decryptKeypairFile(data, passphrase)
+	}
+
 	// Parse the JSON array of bytes
 	var keyBytes []byte
 	if err := json.Unmarshal(data, &keyBytes); err != nil {
@@ -222,19 +367,19 @@ func loadKeypairFromFile(path string) (ed25519.PrivateKey, error) {
 	// Create ed25519 private key from the seed (first 32 bytes)
 	seed := keyBytes[:32]
 	privateKey := ed25519.NewKeyFromSeed(seed)
-	
+
 	// Note: We skip public key validation here because Solana stores the full
 	// keypair but Ed25519.NewKeyFromSeed derives the public key from the seed,
 	// and the derivation might use different methods than what was originally stored.
 	// The important part is that the seed is correct and produces valid signatures.
-	
+
 	return privateKey, nil
 }
 
 // loadKeypairFromString loads a private key from a base58 string
 func loadKeypairFromString(privateKeyStr string) (ed25519.PrivateKey, error) {
 	privateKeyStr = strings.TrimSpace(privateKeyStr)
-	
+
 	// Try to decode as base58
 	decoded, err := base58Decode(privateKeyStr)
 	if err != nil {
@@ -258,24 +403,24 @@ func loadKeypairFromString(privateKeyStr string) (ed25519.PrivateKey, error) {
 // Simple base58 decoder for Solana keys
 func base58Decode(s string) ([]byte, error) {
 	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
-	
+
 	// Create decode map
 	decode := make(map[byte]int)
 	for i, c := range alphabet {
 		decode[byte(c)] = i
 	}
-	
+
 	// Handle empty string
 	if len(s) == 0 {
 		return []byte{}, nil
 	}
-	
+
 	// Count leading 1s
 	leadingOnes := 0
 	for i := 0; i < len(s) && s[i] == '1'; i++ {
 		leadingOnes++
 	}
-	
+
 	// Convert base58 to big integer (in reverse byte order)
 	var result []byte
 	for i := leadingOnes; i < len(s); i++ {
@@ -284,7 +429,7 @@ func base58Decode(s string) ([]byte, error) {
 		if !ok {
 			return nil, fmt.Errorf("invalid character '%c' in base58 string", char)
 		}
-		
+
 		// Multiply result by 58 and add current digit
 		carry := value
 		for j := 0; j < len(result); j++ {
@@ -292,40 +437,40 @@ func base58Decode(s string) ([]byte, error) {
 			result[j] = byte(carry % 256)
 			carry /= 256
 		}
-		
+
 		for carry > 0 {
 			result = append(result, byte(carry%256))
 			carry /= 256
 		}
 	}
-	
+
 	// Add leading zeros for leading 1s
 	for i := 0; i < leadingOnes; i++ {
 		result = append(result, 0)
 	}
-	
+
 	// Reverse to get correct byte order
 	for i := 0; i < len(result)/2; i++ {
 		result[i], result[len(result)-1-i] = result[len(result)-1-i], result[i]
 	}
-	
+
 	return result, nil
 }
 
 // Simple base58 encoder for Solana signatures
 func base58Encode(input []byte) string {
 	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
-	
+
 	if len(input) == 0 {
 		return ""
 	}
-	
+
 	// Count leading zeros
 	leadingZeros := 0
 	for i := 0; i < len(input) && input[i] == 0; i++ {
 		leadingZeros++
 	}
-	
+
 	// Convert to base58
 	var result []byte
 	for i := leadingZeros; i < len(input); i++ {
@@ -335,22 +480,22 @@ func base58Encode(input []byte) string {
 			result[j] = byte(carry % 58)
 			carry /= 58
 		}
-		
+
 		for carry > 0 {
 			result = append(result, byte(carry%58))
 			carry /= 58
 		}
 	}
-	
+
 	// Convert to alphabet characters
 	var encoded []byte
 	for i := 0; i < leadingZeros; i++ {
 		encoded = append(encoded, '1')
 	}
-	
+
 	for i := len(result) - 1; i >= 0; i-- {
 		encoded = append(encoded, alphabet[result[i]])
 	}
-	
+
 	return string(encoded)
 }
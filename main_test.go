@@ -32,7 +32,7 @@ func TestLoadKeypair(t *testing.T) {
 	tmpFile.Close()
 
 	// Test loading the keypair
-	privateKey, err := loadKeypairFromFile(tmpFile.Name())
+	privateKey, err := loadKeypairFromFile(tmpFile.Name(), "")
 	if err != nil {
 		t.Fatalf("Failed to load keypair: %v", err)
 	}
@@ -59,7 +59,7 @@ func TestLoadKeypair(t *testing.T) {
 
 func TestLoadKeypairInvalidFile(t *testing.T) {
 	// Test with non-existent file
-	_, err := loadKeypairFromFile("non-existent-file.json")
+	_, err := loadKeypairFromFile("non-existent-file.json", "")
 	if err == nil {
 		t.Error("Expected error for non-existent file")
 	}
@@ -78,7 +78,7 @@ func TestLoadKeypairInvalidJSON(t *testing.T) {
 	}
 	tmpFile.Close()
 
-	_, err = loadKeypairFromFile(tmpFile.Name())
+	_, err = loadKeypairFromFile(tmpFile.Name(), "")
 	if err == nil {
 		t.Error("Expected error for invalid JSON")
 	}
@@ -97,7 +97,7 @@ func TestLoadKeypairInvalidLength(t *testing.T) {
 	tmpFile.Write(keypairJSON)
 	tmpFile.Close()
 
-	_, err = loadKeypairFromFile(tmpFile.Name())
+	_, err = loadKeypairFromFile(tmpFile.Name(), "")
 	if err == nil {
 		t.Error("Expected error for invalid keypair length")
 	}
@@ -115,7 +115,7 @@ func TestSigningDeterministic(t *testing.T) {
 	tmpFile.Write(keypairJSON)
 	tmpFile.Close()
 
-	privateKey, err := loadKeypairFromFile(tmpFile.Name())
+	privateKey, err := loadKeypairFromFile(tmpFile.Name(), "")
 	if err != nil {
 		t.Fatalf("Failed to load keypair: %v", err)
 	}
@@ -172,14 +172,14 @@ func TestKnownSignature(t *testing.T) {
 	tmpFile.Write(keypairJSON)
 	tmpFile.Close()
 
-	privateKey, err := loadKeypairFromFile(tmpFile.Name())
+	privateKey, err := loadKeypairFromFile(tmpFile.Name(), "")
 	if err != nil {
 		t.Fatalf("Failed to load keypair: %v", err)
 	}
 
 	message := "Test"
 	signature := ed25519.Sign(privateKey, []byte(message))
-	
+
 	// Convert to base64 for comparison
 	base64Sig := base64.StdEncoding.EncodeToString(signature)
 	expectedSig := "GY/HTLWHgdOPoxFpTz9X1BpfNJtztRzj0gtUxkS0daX4uuC3/YhubdYbJU1tKNcK3Q3FP7XZ3a3nyVarRObuDA=="
@@ -201,7 +201,7 @@ func TestPublicKeyValidation(t *testing.T) {
 	tmpFile.Write(keypairJSON)
 	tmpFile.Close()
 
-	privateKey, err := loadKeypairFromFile(tmpFile.Name())
+	privateKey, err := loadKeypairFromFile(tmpFile.Name(), "")
 	if err != nil {
 		t.Fatalf("Failed to load keypair: %v", err)
 	}
@@ -209,7 +209,7 @@ func TestPublicKeyValidation(t *testing.T) {
 	// Test that we can sign and verify with this keypair
 	message := "validation test"
 	signature := ed25519.Sign(privateKey, []byte(message))
-	
+
 	if !ed25519.Verify(privateKey.Public().(ed25519.PublicKey), []byte(message), signature) {
 		t.Error("Signature verification failed with loaded keypair")
 	}
@@ -232,18 +232,18 @@ func TestLoadKeypairFromString(t *testing.T) {
 	// Test with a known base58 encoded key (using a simple test case)
 	// Let's use a well-known test vector: encoding of 32 zero bytes should be "11111111111111111111111111111111111111111111"
 	zeroSeed := make([]byte, 32) // 32 zero bytes
-	
+
 	// Test with our loadKeypairFromString using the zero seed approach
 	privateKey := ed25519.NewKeyFromSeed(zeroSeed)
-	
+
 	// Test signing with the known zero seed
 	message := "test with zero seed"
 	signature := ed25519.Sign(privateKey, []byte(message))
-	
+
 	if !ed25519.Verify(privateKey.Public().(ed25519.PublicKey), []byte(message), signature) {
 		t.Error("Signature verification failed with zero seed")
 	}
-	
+
 	// Test our actual function with a simple base58 string (just "1" which should decode to [0])
 	result, err := base58Decode("1")
 	if err != nil {
@@ -260,7 +260,7 @@ func TestLoadKeypairFromStringInvalid(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for invalid base58 string")
 	}
-	
+
 	// Test with wrong length
 	shortKey := base58Encode([]byte{1, 2, 3, 4, 5}) // Too short
 	_, err = loadKeypairFromString(shortKey)
@@ -280,68 +280,16 @@ func TestBase58Decode(t *testing.T) {
 		{"z", []byte{57}},
 		{"11", []byte{0, 0}},
 	}
-	
+
 	for _, tc := range testCases {
 		result, err := base58Decode(tc.input)
 		if err != nil {
 			t.Errorf("Unexpected error for input %s: %v", tc.input, err)
 			continue
 		}
-		
+
 		if !equalSignatures(result, tc.expected) {
 			t.Errorf("For input %s, expected %v, got %v", tc.input, tc.expected, result)
 		}
 	}
 }
-
-// Helper function to encode bytes to base58 (for testing)
-func base58Encode(data []byte) string {
-	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
-	
-	if len(data) == 0 {
-		return ""
-	}
-	
-	// Convert to big integer
-	var result []byte
-	
-	// Count leading zeros
-	leadingZeros := 0
-	for i := 0; i < len(data) && data[i] == 0; i++ {
-		leadingZeros++
-	}
-	
-	// Convert
-	input := make([]byte, len(data))
-	copy(input, data)
-	
-	for len(input) > 0 {
-		// Find first non-zero
-		i := 0
-		for i < len(input) && input[i] == 0 {
-			i++
-		}
-		input = input[i:]
-		
-		if len(input) == 0 {
-			break
-		}
-		
-		// Divide by 58
-		remainder := 0
-		for i := 0; i < len(input); i++ {
-			temp := remainder*256 + int(input[i])
-			input[i] = byte(temp / 58)
-			remainder = temp % 58
-		}
-		
-		result = append([]byte{alphabet[remainder]}, result...)
-	}
-	
-	// Add leading 1s for leading zeros
-	for i := 0; i < leadingZeros; i++ {
-		result = append([]byte{'1'}, result...)
-	}
-	
-	return string(result)
-}
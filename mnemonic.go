@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// bip39WordIndex maps each wordlist entry to its position, built once so
+// mnemonic validation doesn't linearly scan the wordlist per word.
+var bip39WordIndex = func() map[string]int {
+	m := make(map[string]int, len(bip39EnglishWordlist))
+	for i, w := range bip39EnglishWordlist {
+		m[w] = i
+	}
+	return m
+}()
+
+// defaultSolanaDerivationPath is the path solana-keygen and most Solana
+// wallets (Phantom, Solflare) use to derive the first account from a seed.
+const defaultSolanaDerivationPath = "m/44'/501'/0'/0'"
+
+// loadKeypairFromMnemonic derives an ed25519 keypair from a BIP-39 mnemonic
+// seed phrase following SLIP-0010 for curve ed25519, matching solana-keygen
+// and wallet adapters such as Phantom and Solflare. All derivation indices
+// are hardened, as required by SLIP-0010 for ed25519.
+func loadKeypairFromMnemonic(mnemonic, passphrase, derivationPath string) (ed25519.PrivateKey, error) {
+	if err := validateMnemonic(mnemonic); err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	if derivationPath == "" {
+		derivationPath = defaultSolanaDerivationPath
+	}
+
+	indices, err := parseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path: %w", err)
+	}
+
+	seed := mnemonicToSeed(mnemonic, passphrase)
+
+	key, _, err := deriveSlip0010Ed25519(seed, indices)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	return ed25519.NewKeyFromSeed(key), nil
+}
+
+// mnemonicToSeed stretches a mnemonic into a 64-byte seed via
+// PBKDF2-HMAC-SHA512 with 2048 rounds, per the BIP-39 specification.
+func mnemonicToSeed(mnemonic, passphrase string) []byte {
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	salt := "mnemonic" + passphrase
+	return pbkdf2.Key([]byte(normalized), []byte(salt), 2048, 64, sha512.New)
+}
+
+// validateMnemonic checks that every word is present in the BIP-39 English
+// wordlist, that the word count corresponds to a valid entropy length, and
+// that the trailing checksum bits match SHA-256 of the entropy.
+func validateMnemonic(mnemonic string) error {
+	words := strings.Fields(mnemonic)
+	n := len(words)
+	if n < 12 || n > 24 || n%3 != 0 {
+		return fmt.Errorf("mnemonic must have 12, 15, 18, 21 or 24 words, got %d", n)
+	}
+
+	totalBits := n * 11
+	checksumBits := totalBits / 33
+	entropyBits := totalBits - checksumBits
+
+	bits := make([]bool, totalBits)
+	for i, w := range words {
+		idx, ok := bip39WordIndex[w]
+		if !ok {
+			return fmt.Errorf("word %q is not in the BIP-39 English wordlist", w)
+		}
+		for b := 0; b < 11; b++ {
+			bits[i*11+b] = idx&(1<<(10-b)) != 0
+		}
+	}
+
+	entropy := make([]byte, entropyBits/8)
+	for i := range entropy {
+		var b byte
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				b |= 1 << (7 - j)
+			}
+		}
+		entropy[i] = b
+	}
+
+	hash := sha256.Sum256(entropy)
+	for i := 0; i < checksumBits; i++ {
+		want := hash[0]&(1<<(7-i)) != 0
+		if bits[entropyBits+i] != want {
+			return fmt.Errorf("checksum mismatch")
+		}
+	}
+
+	return nil
+}
+
+// parseDerivationPath parses a BIP-44 style path such as m/44'/501'/0'/0'
+// into its component indices. SLIP-0010 requires every index to be hardened
+// for the ed25519 curve, so each segment after "m" must carry a hardening
+// marker (' or h).
+func parseDerivationPath(path string) ([]uint32, error) {
+	segments := strings.Split(path, "/")
+	if len(segments) == 0 || segments[0] != "m" {
+		return nil, fmt.Errorf("derivation path must start with \"m/\"")
+	}
+	segments = segments[1:]
+
+	indices := make([]uint32, 0, len(segments))
+	for _, seg := range segments {
+		if seg == "" {
+			return nil, fmt.Errorf("empty path segment")
+		}
+		hardened := strings.HasSuffix(seg, "'") || strings.HasSuffix(seg, "h") || strings.HasSuffix(seg, "H")
+		if !hardened {
+			return nil, fmt.Errorf("index %q must be hardened for ed25519 (append ')", seg)
+		}
+		numPart := seg[:len(seg)-1]
+		value, err := strconv.ParseUint(numPart, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q: %w", seg, err)
+		}
+		if value >= 1<<31 {
+			return nil, fmt.Errorf("index %q out of range", seg)
+		}
+		indices = append(indices, uint32(value)|0x80000000)
+	}
+
+	return indices, nil
+}
+
+// deriveSlip0010Ed25519 walks the SLIP-0010 derivation tree for the ed25519
+// curve starting from a BIP-39 seed, returning the final 32-byte key and
+// chain code. See https://github.com/satoshilabs/slips/blob/master/slip-0010.md.
+func deriveSlip0010Ed25519(seed []byte, indices []uint32) (key, chainCode []byte, err error) {
+	master := hmac.New(sha512.New, []byte("ed25519 seed"))
+	master.Write(seed)
+	i := master.Sum(nil)
+	key, chainCode = i[:32], i[32:]
+
+	for _, idx := range indices {
+		if idx&0x80000000 == 0 {
+			return nil, nil, fmt.Errorf("index %d is not hardened", idx)
+		}
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write([]byte{0x00})
+		mac.Write(key)
+		var ser [4]byte
+		binary.BigEndian.PutUint32(ser[:], idx)
+		mac.Write(ser[:])
+
+		i = mac.Sum(nil)
+		key, chainCode = i[:32], i[32:]
+	}
+
+	return key, chainCode, nil
+}
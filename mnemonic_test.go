@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+// testMnemonic is the well-known all-"abandon" BIP-39 test mnemonic.
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// testMnemonicDerivationPath is the default solana-keygen/wallet-adapter
+// path used for the expected-vector tests below.
+const testMnemonicDerivationPath = "m/44'/501'/0'/0'"
+
+// testMnemonicExpectedPublicKey is the base58 public key that
+// m/44'/501'/0'/0' derives to from testMnemonic. solana-keygen itself isn't
+// available in this environment to generate the vector directly, so it was
+// computed with an independent from-spec implementation of PBKDF2-HMAC-SHA512
+// seeding plus SLIP-0010 ed25519 derivation (a separate Python program, not
+// this package's code) and cross-checked byte-for-byte against
+// loadKeypairFromMnemonic's output.
+const testMnemonicExpectedPublicKey = "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk"
+
+func TestValidateMnemonic(t *testing.T) {
+	if err := validateMnemonic(testMnemonic); err != nil {
+		t.Fatalf("expected valid mnemonic, got error: %v", err)
+	}
+}
+
+func TestValidateMnemonicBadChecksum(t *testing.T) {
+	bad := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"
+	if err := validateMnemonic(bad); err == nil {
+		t.Error("expected checksum error for invalid mnemonic")
+	}
+}
+
+func TestValidateMnemonicUnknownWord(t *testing.T) {
+	bad := "notaword abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	if err := validateMnemonic(bad); err == nil {
+		t.Error("expected error for word not in wordlist")
+	}
+}
+
+func TestValidateMnemonicWrongWordCount(t *testing.T) {
+	if err := validateMnemonic("abandon abandon"); err == nil {
+		t.Error("expected error for invalid word count")
+	}
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	indices, err := parseDerivationPath("m/44'/501'/0'/0'")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []uint32{44 | 0x80000000, 501 | 0x80000000, 0 | 0x80000000, 0 | 0x80000000}
+	if len(indices) != len(want) {
+		t.Fatalf("expected %d indices, got %d", len(want), len(indices))
+	}
+	for i := range want {
+		if indices[i] != want[i] {
+			t.Errorf("index %d: expected %d, got %d", i, want[i], indices[i])
+		}
+	}
+}
+
+func TestParseDerivationPathRequiresHardened(t *testing.T) {
+	if _, err := parseDerivationPath("m/44/501'/0'/0'"); err == nil {
+		t.Error("expected error for non-hardened index")
+	}
+}
+
+func TestParseDerivationPathRequiresLeadingM(t *testing.T) {
+	if _, err := parseDerivationPath("44'/501'/0'/0'"); err == nil {
+		t.Error("expected error for path missing leading m")
+	}
+}
+
+func TestLoadKeypairFromMnemonic(t *testing.T) {
+	keypair, err := loadKeypairFromMnemonic(testMnemonic, "", testMnemonicDerivationPath)
+	if err != nil {
+		t.Fatalf("failed to derive keypair: %v", err)
+	}
+	if len(keypair) != ed25519.PrivateKeySize {
+		t.Fatalf("expected private key size %d, got %d", ed25519.PrivateKeySize, len(keypair))
+	}
+
+	message := []byte("test message")
+	signature := ed25519.Sign(keypair, message)
+	if !ed25519.Verify(keypair.Public().(ed25519.PublicKey), message, signature) {
+		t.Error("signature verification failed for derived keypair")
+	}
+}
+
+// TestLoadKeypairFromMnemonicKnownVector checks the derived public key
+// against a known, independently computed vector rather than only
+// round-tripping a signature against itself, so a wrong HMAC key order,
+// missing hardening, or an off-by-one in the path walk would be caught
+// rather than silently cancelling out. See testMnemonicExpectedPublicKey.
+func TestLoadKeypairFromMnemonicKnownVector(t *testing.T) {
+	keypair, err := loadKeypairFromMnemonic(testMnemonic, "", testMnemonicDerivationPath)
+	if err != nil {
+		t.Fatalf("failed to derive keypair: %v", err)
+	}
+
+	pub := keypair.Public().(ed25519.PublicKey)
+	if got := base58Encode(pub); got != testMnemonicExpectedPublicKey {
+		t.Errorf("expected public key %s, got %s", testMnemonicExpectedPublicKey, got)
+	}
+}
+
+func TestLoadKeypairFromMnemonicDefaultPath(t *testing.T) {
+	withDefault, err := loadKeypairFromMnemonic(testMnemonic, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	explicit, err := loadKeypairFromMnemonic(testMnemonic, "", defaultSolanaDerivationPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hex.EncodeToString(withDefault) != hex.EncodeToString(explicit) {
+		t.Error("expected default derivation path to match explicit default path")
+	}
+}
+
+func TestLoadKeypairFromMnemonicInvalidMnemonic(t *testing.T) {
+	_, err := loadKeypairFromMnemonic("not a valid mnemonic at all", "", "")
+	if err == nil {
+		t.Error("expected error for invalid mnemonic")
+	}
+}
+
+func TestLoadKeypairFromMnemonicInvalidPath(t *testing.T) {
+	_, err := loadKeypairFromMnemonic(testMnemonic, "", "m/44/501'/0'/0'")
+	if err == nil {
+		t.Error("expected error for non-hardened derivation path")
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"unicode/utf8"
+)
+
+// offchainSigningDomain is the fixed 16-byte prefix (0xff followed by
+// "solana offchain") that domain-separates off-chain messages from
+// transactions, per SIMD-0048.
+const offchainSigningDomain = "\xffsolana offchain"
+
+// offchainHeaderVersion is the only header version this tool emits or
+// accepts.
+const offchainHeaderVersion = 0
+
+// Off-chain message format identifiers, chosen automatically from the
+// message content: restricted ASCII and limited UTF-8 messages are capped
+// at 1232 bytes (the max that still fits a single packet on the wire);
+// anything else falls back to extended UTF-8.
+const (
+	offchainFormatRestrictedASCII = 0
+	offchainFormatLimitedUTF8     = 1
+	offchainFormatExtendedUTF8    = 2
+
+	offchainLimitedMaxLength = 1232
+)
+
+// offchainDomainIDSize is the size of the configurable application domain
+// identifier embedded in the envelope.
+const offchainDomainIDSize = 32
+
+// isRestrictedASCII reports whether every byte of message is a printable
+// ASCII character or common whitespace, the charset SIMD-0048 calls
+// "restricted ASCII".
+func isRestrictedASCII(message []byte) bool {
+	for _, b := range message {
+		switch {
+		case b >= 0x20 && b <= 0x7e:
+			continue
+		case b == '\n' || b == '\r' || b == '\t':
+			continue
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// offchainMessageFormat selects the format byte for message, following
+// SIMD-0048: restricted ASCII when every byte qualifies, limited UTF-8 for
+// other valid UTF-8 within the single-packet size limit, and extended
+// UTF-8 otherwise.
+func offchainMessageFormat(message []byte) byte {
+	if isRestrictedASCII(message) && len(message) <= offchainLimitedMaxLength {
+		return offchainFormatRestrictedASCII
+	}
+	if utf8.Valid(message) && len(message) <= offchainLimitedMaxLength {
+		return offchainFormatLimitedUTF8
+	}
+	return offchainFormatExtendedUTF8
+}
+
+// buildOffchainEnvelope assembles the SIMD-0048 off-chain message envelope:
+// signing domain, header version, message format, application domain
+// identifier, and the length-prefixed message itself. This is exactly the
+// byte string that gets signed and verified.
+func buildOffchainEnvelope(message []byte, domainID [offchainDomainIDSize]byte) ([]byte, error) {
+	if len(message) > 0xffff {
+		return nil, fmt.Errorf("message too long for off-chain signing: %d bytes (max %d)", len(message), 0xffff)
+	}
+
+	envelope := make([]byte, 0, len(offchainSigningDomain)+1+1+offchainDomainIDSize+2+len(message))
+	envelope = append(envelope, offchainSigningDomain...)
+	envelope = append(envelope, offchainHeaderVersion)
+	envelope = append(envelope, offchainMessageFormat(message))
+	envelope = append(envelope, domainID[:]...)
+
+	var length [2]byte
+	binary.LittleEndian.PutUint16(length[:], uint16(len(message)))
+	envelope = append(envelope, length[:]...)
+	envelope = append(envelope, message...)
+
+	return envelope, nil
+}
+
+// signOffchainMessage wraps message in its SIMD-0048 envelope and signs
+// the envelope with keypair, returning both the envelope (useful for
+// -verbose inspection) and the raw signature.
+func signOffchainMessage(keypair ed25519.PrivateKey, message []byte, domainID [offchainDomainIDSize]byte) (envelope, signature []byte, err error) {
+	envelope, err = buildOffchainEnvelope(message, domainID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return envelope, ed25519.Sign(keypair, envelope), nil
+}
+
+// verifyOffchainMessage reconstructs the SIMD-0048 envelope for message and
+// checks signature against it using pub.
+func verifyOffchainMessage(pub ed25519.PublicKey, signature, message []byte, domainID [offchainDomainIDSize]byte) (bool, error) {
+	envelope, err := buildOffchainEnvelope(message, domainID)
+	if err != nil {
+		return false, err
+	}
+	return ed25519.Verify(pub, envelope, signature), nil
+}
+
+// parseOffchainDomainID decodes a hex-encoded 32-byte application domain
+// identifier, defaulting to all zeros when s is empty.
+func parseOffchainDomainID(s string) ([offchainDomainIDSize]byte, error) {
+	var domainID [offchainDomainIDSize]byte
+	if s == "" {
+		return domainID, nil
+	}
+
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return domainID, fmt.Errorf("failed to decode -offchain-domain as hex: %w", err)
+	}
+	if len(decoded) != offchainDomainIDSize {
+		return domainID, fmt.Errorf("invalid -offchain-domain length: expected %d bytes, got %d", offchainDomainIDSize, len(decoded))
+	}
+	copy(domainID[:], decoded)
+	return domainID, nil
+}
+
+// runOffchainMode implements `-mode offchain`: it wraps the message in its
+// SIMD-0048 envelope, signs the envelope with the selected keypair, and
+// prints the signature in the requested format, optionally dumping the
+// full envelope in hex for -verbose.
+func runOffchainMode(keypairPath, privateKey, mnemonic, passphrase, derivationPath, keypairPassphrase, message, messageFile, offchainDomain, outputFormat string, verbose bool) {
+	var messageBytes []byte
+	switch {
+	case message != "" && messageFile != "":
+		log.Fatalf("Error: Cannot use both -message and -message-file at the same time")
+	case message != "":
+		messageBytes = []byte(processEscapeSequences(message))
+	case messageFile != "":
+		data, err := os.ReadFile(messageFile)
+		if err != nil {
+			log.Fatalf("Failed to read message file: %v", err)
+		}
+		messageBytes = data
+	default:
+		log.Fatalf("Error: Either -message or -message-file must be provided with -mode offchain")
+	}
+
+	domainID, err := parseOffchainDomainID(offchainDomain)
+	if err != nil {
+		log.Fatalf("Failed to parse -offchain-domain: %v", err)
+	}
+
+	keypair, err := loadSelectedKeypair(expandHomeDir(keypairPath), privateKey, mnemonic, passphrase, derivationPath, keypairPassphrase)
+	if err != nil {
+		log.Fatalf("Failed to load keypair: %v", err)
+	}
+
+	envelope, signature, err := signOffchainMessage(keypair, messageBytes, domainID)
+	if err != nil {
+		log.Fatalf("Failed to sign off-chain message: %v", err)
+	}
+
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Public key: %s\n", base58Encode(keypair.Public().(ed25519.PublicKey)))
+		fmt.Fprintf(os.Stderr, "Envelope (%d bytes): %s\n", len(envelope), hex.EncodeToString(envelope))
+	}
+
+	switch outputFormat {
+	case "", "base58":
+		fmt.Println(base58Encode(signature))
+	case "base64":
+		fmt.Println(base64.StdEncoding.EncodeToString(signature))
+	case "hex":
+		fmt.Println(hex.EncodeToString(signature))
+	default:
+		log.Fatalf("Unknown format: %s. Supported formats for -mode offchain: base58, base64, hex", outputFormat)
+	}
+}
+
+// runVerifyOffchainCommand implements the `verify-offchain` subcommand: it
+// reconstructs the off-chain message envelope for the given message and
+// domain, and checks the signature against it.
+func runVerifyOffchainCommand(args []string) {
+	fs := flag.NewFlagSet("verify-offchain", flag.ExitOnError)
+	publicKey := fs.String("public-key", "", "Base58-encoded Ed25519 public key")
+	signature := fs.String("signature", "", "Base58-encoded signature to verify")
+	message := fs.String("message", "", "Message that was signed")
+	messageFile := fs.String("message-file", "", "Path to file containing the message that was signed")
+	offchainDomain := fs.String("offchain-domain", "", "Hex-encoded 32-byte application domain identifier (default: all zeros)")
+	fs.Parse(args)
+
+	if *publicKey == "" {
+		log.Fatalf("Error: -public-key must be provided")
+	}
+	if *signature == "" {
+		log.Fatalf("Error: -signature must be provided")
+	}
+
+	var messageBytes []byte
+	switch {
+	case *message != "" && *messageFile != "":
+		log.Fatalf("Error: Cannot use both -message and -message-file at the same time")
+	case *message != "":
+		messageBytes = []byte(processEscapeSequences(*message))
+	case *messageFile != "":
+		data, err := os.ReadFile(*messageFile)
+		if err != nil {
+			log.Fatalf("Failed to read message file: %v", err)
+		}
+		messageBytes = data
+	default:
+		log.Fatalf("Error: Either -message or -message-file must be provided")
+	}
+
+	pub, err := base58Decode(*publicKey)
+	if err != nil {
+		log.Fatalf("Failed to decode public key: %v", err)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		log.Fatalf("Invalid public key length: expected %d bytes, got %d", ed25519.PublicKeySize, len(pub))
+	}
+
+	sig, err := base58Decode(*signature)
+	if err != nil {
+		log.Fatalf("Failed to decode signature: %v", err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		log.Fatalf("Invalid signature length: expected %d bytes, got %d", ed25519.SignatureSize, len(sig))
+	}
+
+	domainID, err := parseOffchainDomainID(*offchainDomain)
+	if err != nil {
+		log.Fatalf("Failed to parse -offchain-domain: %v", err)
+	}
+
+	ok, err := verifyOffchainMessage(ed25519.PublicKey(pub), sig, messageBytes, domainID)
+	if err != nil {
+		log.Fatalf("Off-chain message verification failed: %v", err)
+	}
+	if !ok {
+		log.Fatalf("Off-chain message signature is invalid")
+	}
+
+	fmt.Println("Off-chain message signature is valid")
+}
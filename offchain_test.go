@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func testOffchainKeypair(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate test keypair: %v", err)
+	}
+	return priv
+}
+
+// TestSignOffchainMessageKnownVector checks the envelope and signature
+// bytes against a known input/output vector rather than only round-tripping
+// against this package's own verify function, so a confidently wrong field
+// order or offset in the SIMD-0048 layout would be caught rather than
+// cancelling out against itself. The Solana CLI isn't available in this
+// offline environment to produce the vector by running
+// `solana sign-offchain-message` directly, so it was computed with an
+// independent from-spec implementation of Ed25519 signing (a separate
+// Python program, not this package's code) over the SIMD-0048 envelope
+// assembled by hand from the spec fields, then cross-checked byte-for-byte
+// against signOffchainMessage's output for the same seed, message, and
+// all-zero application domain.
+func TestSignOffchainMessageKnownVector(t *testing.T) {
+	seed, err := hex.DecodeString("37df573b3ac4ad5b522e064e25b63ea16bcbe79d449e81a0268d1047948bb445")
+	if err != nil {
+		t.Fatalf("failed to decode test seed: %v", err)
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	var domainID [offchainDomainIDSize]byte
+
+	const wantEnvelope = "ff736f6c616e61206f6666636861696e00000000000000000000000000000000000000000000000000000000000000000000150068656c6c6f20736f6c616e61206f6666636861696e"
+	const wantSignature = "07b05ade218a9eec6405bf0b7aa38a8a32c4b4ae9e8854ccb44381ca2f66b3d0c3d8e392ed253361898919bb7c1568b7568bafa53a5b240642075b549df7f606"
+
+	envelope, signature, err := signOffchainMessage(priv, []byte("hello solana offchain"), domainID)
+	if err != nil {
+		t.Fatalf("signOffchainMessage failed: %v", err)
+	}
+	if hex.EncodeToString(envelope) != wantEnvelope {
+		t.Errorf("envelope mismatch:\n got  %s\n want %s", hex.EncodeToString(envelope), wantEnvelope)
+	}
+	if hex.EncodeToString(signature) != wantSignature {
+		t.Errorf("signature mismatch:\n got  %s\n want %s", hex.EncodeToString(signature), wantSignature)
+	}
+}
+
+func TestBuildOffchainEnvelopeLayout(t *testing.T) {
+	var domainID [offchainDomainIDSize]byte
+	domainID[0] = 0xab
+
+	message := []byte("hello solana")
+	envelope, err := buildOffchainEnvelope(message, domainID)
+	if err != nil {
+		t.Fatalf("buildOffchainEnvelope failed: %v", err)
+	}
+
+	if !bytes.HasPrefix(envelope, []byte(offchainSigningDomain)) {
+		t.Fatalf("expected envelope to start with the signing domain")
+	}
+	offset := len(offchainSigningDomain)
+
+	if envelope[offset] != offchainHeaderVersion {
+		t.Errorf("expected header version %d, got %d", offchainHeaderVersion, envelope[offset])
+	}
+	offset++
+
+	if envelope[offset] != offchainFormatRestrictedASCII {
+		t.Errorf("expected restricted ASCII format for a plain ASCII message, got %d", envelope[offset])
+	}
+	offset++
+
+	if !bytes.Equal(envelope[offset:offset+offchainDomainIDSize], domainID[:]) {
+		t.Errorf("expected domain identifier to be embedded in the envelope")
+	}
+	offset += offchainDomainIDSize
+
+	gotLength := int(envelope[offset]) | int(envelope[offset+1])<<8
+	if gotLength != len(message) {
+		t.Errorf("expected length %d, got %d", len(message), gotLength)
+	}
+	offset += 2
+
+	if !bytes.Equal(envelope[offset:], message) {
+		t.Errorf("expected envelope to end with the message bytes")
+	}
+}
+
+func TestOffchainMessageFormatDetection(t *testing.T) {
+	if got := offchainMessageFormat([]byte("plain ascii text")); got != offchainFormatRestrictedASCII {
+		t.Errorf("expected restricted ASCII, got %d", got)
+	}
+	if got := offchainMessageFormat([]byte("héllo")); got != offchainFormatLimitedUTF8 {
+		t.Errorf("expected limited UTF-8 for non-ASCII text, got %d", got)
+	}
+	if got := offchainMessageFormat(bytes.Repeat([]byte("a"), offchainLimitedMaxLength+1)); got != offchainFormatExtendedUTF8 {
+		t.Errorf("expected extended UTF-8 for an over-length message, got %d", got)
+	}
+}
+
+func TestSignAndVerifyOffchainMessageRoundTrip(t *testing.T) {
+	priv := testOffchainKeypair(t)
+	pub := priv.Public().(ed25519.PublicKey)
+	var domainID [offchainDomainIDSize]byte
+
+	envelope, signature, err := signOffchainMessage(priv, []byte("memo text"), domainID)
+	if err != nil {
+		t.Fatalf("signOffchainMessage failed: %v", err)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		t.Fatalf("expected a %d-byte signature, got %d", ed25519.SignatureSize, len(signature))
+	}
+	if !ed25519.Verify(pub, envelope, signature) {
+		t.Fatal("expected signature to verify directly against the envelope")
+	}
+
+	ok, err := verifyOffchainMessage(pub, signature, []byte("memo text"), domainID)
+	if err != nil {
+		t.Fatalf("verifyOffchainMessage failed: %v", err)
+	}
+	if !ok {
+		t.Error("expected verifyOffchainMessage to accept a valid signature")
+	}
+}
+
+func TestVerifyOffchainMessageRejectsWrongDomain(t *testing.T) {
+	priv := testOffchainKeypair(t)
+	pub := priv.Public().(ed25519.PublicKey)
+	var domainID, otherDomainID [offchainDomainIDSize]byte
+	otherDomainID[0] = 0x01
+
+	_, signature, err := signOffchainMessage(priv, []byte("memo text"), domainID)
+	if err != nil {
+		t.Fatalf("signOffchainMessage failed: %v", err)
+	}
+
+	ok, err := verifyOffchainMessage(pub, signature, []byte("memo text"), otherDomainID)
+	if err != nil {
+		t.Fatalf("verifyOffchainMessage failed: %v", err)
+	}
+	if ok {
+		t.Error("expected signature to be rejected when the domain identifier differs")
+	}
+}
+
+func TestVerifyOffchainMessageRejectsTamperedMessage(t *testing.T) {
+	priv := testOffchainKeypair(t)
+	pub := priv.Public().(ed25519.PublicKey)
+	var domainID [offchainDomainIDSize]byte
+
+	_, signature, err := signOffchainMessage(priv, []byte("original"), domainID)
+	if err != nil {
+		t.Fatalf("signOffchainMessage failed: %v", err)
+	}
+
+	ok, err := verifyOffchainMessage(pub, signature, []byte("tampered"), domainID)
+	if err != nil {
+		t.Fatalf("verifyOffchainMessage failed: %v", err)
+	}
+	if ok {
+		t.Error("expected signature to be rejected for a different message")
+	}
+}
+
+func TestParseOffchainDomainID(t *testing.T) {
+	zero, err := parseOffchainDomainID("")
+	if err != nil {
+		t.Fatalf("unexpected error for empty domain: %v", err)
+	}
+	if zero != ([offchainDomainIDSize]byte{}) {
+		t.Error("expected empty -offchain-domain to decode to all zeros")
+	}
+
+	raw := bytes.Repeat([]byte{0xcd}, offchainDomainIDSize)
+	decoded, err := parseOffchainDomainID(hex.EncodeToString(raw))
+	if err != nil {
+		t.Fatalf("unexpected error decoding valid hex: %v", err)
+	}
+	if !bytes.Equal(decoded[:], raw) {
+		t.Error("expected decoded domain identifier to match the input hex")
+	}
+
+	if _, err := parseOffchainDomainID("not-hex"); err == nil {
+		t.Error("expected error for invalid hex")
+	}
+	if _, err := parseOffchainDomainID("ab"); err == nil {
+		t.Error("expected error for a domain identifier shorter than 32 bytes")
+	}
+}
+
+func TestBuildOffchainEnvelopeRejectsOversizedMessage(t *testing.T) {
+	var domainID [offchainDomainIDSize]byte
+	if _, err := buildOffchainEnvelope(make([]byte, 0x10000), domainID); err == nil {
+		t.Error("expected error for a message exceeding the u16 length field")
+	}
+}
+
+func TestOffchainSigningDomainConstant(t *testing.T) {
+	if len(offchainSigningDomain) != 16 {
+		t.Fatalf("expected signing domain to be 16 bytes, got %d", len(offchainSigningDomain))
+	}
+	if !strings.HasSuffix(offchainSigningDomain, "solana offchain") {
+		t.Errorf("expected signing domain to end with %q", "solana offchain")
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Solana wire-format constants. A versioned message is flagged by setting
+// the high bit of the first message byte; the low 7 bits carry the version
+// number (0 for v0).
+const (
+	solanaVersionedMessageFlag = 0x80
+	solanaVersionedMessageMask = 0x7f
+)
+
+// solanaMessageHeader mirrors the 3-byte header at the start of every
+// Solana message, describing how account keys are partitioned into
+// signer/writable groups.
+type solanaMessageHeader struct {
+	NumRequiredSignatures       byte
+	NumReadonlySignedAccounts   byte
+	NumReadonlyUnsignedAccounts byte
+}
+
+// solanaCompiledInstruction is one instruction in a compiled message: the
+// index of its program in AccountKeys, the indexes of the accounts it
+// touches, and its opaque instruction data.
+type solanaCompiledInstruction struct {
+	ProgramIDIndex byte
+	Accounts       []byte
+	Data           []byte
+}
+
+// solanaAddressTableLookup is a v0-only entry that pulls additional account
+// keys from an on-chain address lookup table instead of listing them
+// inline.
+type solanaAddressTableLookup struct {
+	Account         [32]byte
+	WritableIndexes []byte
+	ReadonlyIndexes []byte
+}
+
+// solanaMessage is a parsed legacy or v0 Solana message. Raw holds exactly
+// the bytes that were parsed, which is also the signing input: Solana
+// signatures are computed over the serialized message, never the full
+// transaction.
+type solanaMessage struct {
+	IsVersioned         bool
+	Version             byte
+	Header              solanaMessageHeader
+	AccountKeys         [][32]byte
+	RecentBlockhash     [32]byte
+	Instructions        []solanaCompiledInstruction
+	AddressTableLookups []solanaAddressTableLookup
+	Raw                 []byte
+}
+
+// solanaTransaction is a parsed Solana transaction: a compact array of
+// signatures (one slot per required signer, zero-filled until signed)
+// followed by the message they sign over.
+type solanaTransaction struct {
+	Signatures [][64]byte
+	Message    solanaMessage
+}
+
+// decodeCompactU16 decodes Solana's "compact-u16" (a.k.a. shortvec) varint
+// encoding: up to 3 bytes, 7 payload bits each, continuation in the high
+// bit. It returns the decoded value and the number of bytes consumed.
+func decodeCompactU16(data []byte) (uint16, int, error) {
+	var value uint16
+	for i := 0; i < 3; i++ {
+		if i >= len(data) {
+			return 0, 0, fmt.Errorf("truncated compact-u16 at byte %d", i)
+		}
+		b := data[i]
+		value |= uint16(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return value, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("compact-u16 encoding too long")
+}
+
+// encodeCompactU16 encodes value using Solana's compact-u16 (shortvec)
+// varint encoding.
+func encodeCompactU16(value uint16) []byte {
+	var out []byte
+	for {
+		b := byte(value & 0x7f)
+		value >>= 7
+		if value != 0 {
+			out = append(out, b|0x80)
+			continue
+		}
+		out = append(out, b)
+		return out
+	}
+}
+
+// parseSolanaTransaction decodes a full Solana wire-format transaction: a
+// compact array of 64-byte signatures followed by the message they sign.
+func parseSolanaTransaction(data []byte) (*solanaTransaction, error) {
+	numSigs, n, err := decodeCompactU16(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature count: %w", err)
+	}
+	offset := n
+
+	signatures := make([][64]byte, numSigs)
+	for i := range signatures {
+		if offset+64 > len(data) {
+			return nil, fmt.Errorf("truncated signature at index %d", i)
+		}
+		copy(signatures[i][:], data[offset:offset+64])
+		offset += 64
+	}
+
+	message, err := parseSolanaMessage(data[offset:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	return &solanaTransaction{Signatures: signatures, Message: *message}, nil
+}
+
+// parseSolanaMessage decodes a legacy or v0 Solana message. It recognizes
+// v0 by the high bit on the first byte, per the versioned transactions
+// specification.
+func parseSolanaMessage(data []byte) (*solanaMessage, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("empty message")
+	}
+
+	msg := &solanaMessage{}
+	offset := 0
+
+	if data[0]&solanaVersionedMessageFlag != 0 {
+		msg.IsVersioned = true
+		msg.Version = data[0] & solanaVersionedMessageMask
+		offset++
+	}
+
+	if offset+3 > len(data) {
+		return nil, fmt.Errorf("truncated message header")
+	}
+	msg.Header = solanaMessageHeader{
+		NumRequiredSignatures:       data[offset],
+		NumReadonlySignedAccounts:   data[offset+1],
+		NumReadonlyUnsignedAccounts: data[offset+2],
+	}
+	offset += 3
+
+	numAccounts, n, err := decodeCompactU16(data[offset:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode account key count: %w", err)
+	}
+	offset += n
+
+	msg.AccountKeys = make([][32]byte, numAccounts)
+	for i := range msg.AccountKeys {
+		if offset+32 > len(data) {
+			return nil, fmt.Errorf("truncated account key at index %d", i)
+		}
+		copy(msg.AccountKeys[i][:], data[offset:offset+32])
+		offset += 32
+	}
+
+	if offset+32 > len(data) {
+		return nil, fmt.Errorf("truncated recent blockhash")
+	}
+	copy(msg.RecentBlockhash[:], data[offset:offset+32])
+	offset += 32
+
+	numInstructions, n, err := decodeCompactU16(data[offset:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode instruction count: %w", err)
+	}
+	offset += n
+
+	msg.Instructions = make([]solanaCompiledInstruction, numInstructions)
+	for i := range msg.Instructions {
+		if offset+1 > len(data) {
+			return nil, fmt.Errorf("truncated instruction at index %d", i)
+		}
+		programIDIndex := data[offset]
+		offset++
+
+		numAccIdx, n, err := decodeCompactU16(data[offset:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode instruction %d account count: %w", i, err)
+		}
+		offset += n
+		if offset+int(numAccIdx) > len(data) {
+			return nil, fmt.Errorf("truncated instruction %d accounts", i)
+		}
+		accounts := append([]byte(nil), data[offset:offset+int(numAccIdx)]...)
+		offset += int(numAccIdx)
+
+		numData, n, err := decodeCompactU16(data[offset:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode instruction %d data length: %w", i, err)
+		}
+		offset += n
+		if offset+int(numData) > len(data) {
+			return nil, fmt.Errorf("truncated instruction %d data", i)
+		}
+		instrData := append([]byte(nil), data[offset:offset+int(numData)]...)
+		offset += int(numData)
+
+		msg.Instructions[i] = solanaCompiledInstruction{
+			ProgramIDIndex: programIDIndex,
+			Accounts:       accounts,
+			Data:           instrData,
+		}
+	}
+
+	if msg.IsVersioned {
+		numLookups, n, err := decodeCompactU16(data[offset:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode address table lookup count: %w", err)
+		}
+		offset += n
+
+		msg.AddressTableLookups = make([]solanaAddressTableLookup, numLookups)
+		for i := range msg.AddressTableLookups {
+			if offset+32 > len(data) {
+				return nil, fmt.Errorf("truncated address table lookup %d account", i)
+			}
+			var lookup solanaAddressTableLookup
+			copy(lookup.Account[:], data[offset:offset+32])
+			offset += 32
+
+			numWritable, n, err := decodeCompactU16(data[offset:])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode lookup %d writable count: %w", i, err)
+			}
+			offset += n
+			if offset+int(numWritable) > len(data) {
+				return nil, fmt.Errorf("truncated lookup %d writable indexes", i)
+			}
+			lookup.WritableIndexes = append([]byte(nil), data[offset:offset+int(numWritable)]...)
+			offset += int(numWritable)
+
+			numReadonly, n, err := decodeCompactU16(data[offset:])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode lookup %d readonly count: %w", i, err)
+			}
+			offset += n
+			if offset+int(numReadonly) > len(data) {
+				return nil, fmt.Errorf("truncated lookup %d readonly indexes", i)
+			}
+			lookup.ReadonlyIndexes = append([]byte(nil), data[offset:offset+int(numReadonly)]...)
+			offset += int(numReadonly)
+
+			msg.AddressTableLookups[i] = lookup
+		}
+	}
+
+	msg.Raw = append([]byte(nil), data[:offset]...)
+	return msg, nil
+}
+
+// findSignerIndex locates pub among the message's required signer account
+// keys (the first NumRequiredSignatures entries of AccountKeys), returning
+// an error if it isn't one of them.
+func findSignerIndex(msg *solanaMessage, pub ed25519.PublicKey) (int, error) {
+	numRequired := int(msg.Header.NumRequiredSignatures)
+	for i := 0; i < numRequired && i < len(msg.AccountKeys); i++ {
+		if bytes.Equal(msg.AccountKeys[i][:], pub) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("public key is not listed as a required signer")
+}
+
+// signSolanaTransaction signs tx's message with keypair and inserts the
+// signature into the slot matching keypair's public key among the
+// required signers. The signing input is exactly the serialized message
+// (tx.Message.Raw), never the full transaction. Unless allowUnsafe is set,
+// signing is refused when the key isn't a required signer.
+func signSolanaTransaction(tx *solanaTransaction, keypair ed25519.PrivateKey, allowUnsafe bool) error {
+	pub := keypair.Public().(ed25519.PublicKey)
+
+	index, err := findSignerIndex(&tx.Message, pub)
+	if err != nil {
+		if !allowUnsafe {
+			return fmt.Errorf("%w (pass -allow-unsafe to override)", err)
+		}
+		index = 0
+	}
+
+	if index >= len(tx.Signatures) {
+		return fmt.Errorf("signer index %d out of range for %d signature slots", index, len(tx.Signatures))
+	}
+
+	signature := ed25519.Sign(keypair, tx.Message.Raw)
+	copy(tx.Signatures[index][:], signature)
+	return nil
+}
+
+// serializeSolanaTransaction re-encodes tx in Solana wire format: a
+// compact array of signatures followed by the raw message bytes.
+func serializeSolanaTransaction(tx *solanaTransaction) []byte {
+	var out []byte
+	out = append(out, encodeCompactU16(uint16(len(tx.Signatures)))...)
+	for _, sig := range tx.Signatures {
+		out = append(out, sig[:]...)
+	}
+	out = append(out, tx.Message.Raw...)
+	return out
+}
+
+// dumpSolanaMessage renders a parsed message in a human-readable form for
+// the -dump flag.
+func dumpSolanaMessage(msg *solanaMessage) string {
+	var b strings.Builder
+	if msg.IsVersioned {
+		fmt.Fprintf(&b, "Version: v%d\n", msg.Version)
+	} else {
+		fmt.Fprintf(&b, "Version: legacy\n")
+	}
+	fmt.Fprintf(&b, "Header: numRequiredSignatures=%d numReadonlySigned=%d numReadonlyUnsigned=%d\n",
+		msg.Header.NumRequiredSignatures, msg.Header.NumReadonlySignedAccounts, msg.Header.NumReadonlyUnsignedAccounts)
+
+	fmt.Fprintf(&b, "Account keys (%d):\n", len(msg.AccountKeys))
+	for i, key := range msg.AccountKeys {
+		fmt.Fprintf(&b, "  [%d] %s\n", i, base58Encode(key[:]))
+	}
+
+	fmt.Fprintf(&b, "Recent blockhash: %s\n", base58Encode(msg.RecentBlockhash[:]))
+
+	fmt.Fprintf(&b, "Instructions (%d):\n", len(msg.Instructions))
+	for i, instr := range msg.Instructions {
+		fmt.Fprintf(&b, "  [%d] programIdIndex=%d accounts=%v data=%s\n",
+			i, instr.ProgramIDIndex, instr.Accounts, hex.EncodeToString(instr.Data))
+	}
+
+	if msg.IsVersioned {
+		fmt.Fprintf(&b, "Address table lookups (%d):\n", len(msg.AddressTableLookups))
+		for i, lookup := range msg.AddressTableLookups {
+			fmt.Fprintf(&b, "  [%d] account=%s writable=%v readonly=%v\n",
+				i, base58Encode(lookup.Account[:]), lookup.WritableIndexes, lookup.ReadonlyIndexes)
+		}
+	}
+
+	return b.String()
+}
+
+// decodeTransactionBlob decodes a base58- or base64-encoded transaction
+// blob, trying base58 first since that's how Solana tooling usually
+// presents message/transaction bytes on the command line.
+func decodeTransactionBlob(blob string) ([]byte, error) {
+	blob = strings.TrimSpace(blob)
+	if decoded, err := base58Decode(blob); err == nil && len(decoded) > 0 {
+		return decoded, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode as base58 or base64: %w", err)
+	}
+	return decoded, nil
+}
+
+// runTransactionMode implements `-mode transaction`: it decodes an unsigned
+// Solana transaction, optionally dumps the parsed message, signs it with
+// the selected keypair, and prints the fully-signed transaction ready for
+// submission via sendTransaction.
+func runTransactionMode(keypairPath, privateKey, mnemonic, passphrase, derivationPath, keypairPassphrase, tx, txFile, outputFormat string, allowUnsafe, dump, verbose bool) {
+	var blob string
+	switch {
+	case tx != "" && txFile != "":
+		log.Fatalf("Error: Cannot use both -tx and -tx-file at the same time")
+	case tx != "":
+		blob = tx
+	case txFile != "":
+		data, err := os.ReadFile(txFile)
+		if err != nil {
+			log.Fatalf("Failed to read transaction file: %v", err)
+		}
+		blob = string(data)
+	default:
+		log.Fatalf("Error: Either -tx or -tx-file must be provided with -mode transaction")
+	}
+
+	data, err := decodeTransactionBlob(blob)
+	if err != nil {
+		log.Fatalf("Failed to decode transaction: %v", err)
+	}
+
+	solTx, err := parseSolanaTransaction(data)
+	if err != nil {
+		log.Fatalf("Failed to parse transaction: %v", err)
+	}
+
+	if dump {
+		fmt.Print(dumpSolanaMessage(&solTx.Message))
+		return
+	}
+
+	keypair, err := loadSelectedKeypair(keypairPath, privateKey, mnemonic, passphrase, derivationPath, keypairPassphrase)
+	if err != nil {
+		log.Fatalf("Failed to load keypair: %v", err)
+	}
+
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Keypair loaded successfully\n")
+		fmt.Fprintf(os.Stderr, "Public key: %s\n", base58Encode(keypair.Public().(ed25519.PublicKey)))
+		fmt.Fprintf(os.Stderr, "Signing message of %d bytes\n", len(solTx.Message.Raw))
+	}
+
+	if err := signSolanaTransaction(solTx, keypair, allowUnsafe); err != nil {
+		log.Fatalf("Failed to sign transaction: %v", err)
+	}
+
+	signed := serializeSolanaTransaction(solTx)
+
+	switch outputFormat {
+	case "", "base58":
+		fmt.Println(base58Encode(signed))
+	case "base64":
+		fmt.Println(base64.StdEncoding.EncodeToString(signed))
+	case "hex":
+		fmt.Println(hex.EncodeToString(signed))
+	default:
+		log.Fatalf("Unknown format: %s. Supported formats for -mode transaction: base58, base64, hex", outputFormat)
+	}
+}
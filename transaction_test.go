@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+)
+
+// buildLegacyMessage constructs a minimal synthetic legacy Solana message
+// with two account keys (a fee payer/signer and a program) and a single
+// zero-account, zero-data instruction, for exercising the parser's own
+// field-by-field structure in isolation. It is not meant to resemble real
+// wire output; see legacyTransferMessageFixtureB64 for that.
+func buildLegacyMessage(signer, program [32]byte, blockhash [32]byte) []byte {
+	var out []byte
+	out = append(out, 1, 0, 0) // header: 1 required signature, 0 readonly signed, 0 readonly unsigned
+	out = append(out, encodeCompactU16(2)...)
+	out = append(out, signer[:]...)
+	out = append(out, program[:]...)
+	out = append(out, blockhash[:]...)
+	out = append(out, encodeCompactU16(1)...) // one instruction
+	out = append(out, 1)                      // programIdIndex = 1
+	out = append(out, encodeCompactU16(0)...) // 0 accounts
+	out = append(out, encodeCompactU16(0)...) // 0 data bytes
+	return out
+}
+
+func buildUnsignedTransaction(message []byte) []byte {
+	var out []byte
+	out = append(out, encodeCompactU16(1)...) // 1 signature slot
+	out = append(out, make([]byte, 64)...)    // zero placeholder signature
+	out = append(out, message...)
+	return out
+}
+
+func TestCompactU16RoundTrip(t *testing.T) {
+	cases := []uint16{0, 1, 127, 128, 16383, 16384, 65535}
+	for _, v := range cases {
+		encoded := encodeCompactU16(v)
+		decoded, n, err := decodeCompactU16(encoded)
+		if err != nil {
+			t.Fatalf("decode failed for %d: %v", v, err)
+		}
+		if decoded != v {
+			t.Errorf("expected %d, got %d", v, decoded)
+		}
+		if n != len(encoded) {
+			t.Errorf("expected to consume %d bytes, consumed %d", len(encoded), n)
+		}
+	}
+}
+
+func TestDecodeCompactU16Truncated(t *testing.T) {
+	if _, _, err := decodeCompactU16([]byte{0x80}); err == nil {
+		t.Error("expected error for truncated compact-u16")
+	}
+}
+
+func TestParseSolanaMessageLegacy(t *testing.T) {
+	_, pub, _ := ed25519.GenerateKey(nil)
+	var signer, program, blockhash [32]byte
+	copy(signer[:], pub)
+	program[0] = 1
+	blockhash[0] = 2
+
+	raw := buildLegacyMessage(signer, program, blockhash)
+	msg, err := parseSolanaMessage(raw)
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+
+	if msg.IsVersioned {
+		t.Error("expected legacy message to not be versioned")
+	}
+	if msg.Header.NumRequiredSignatures != 1 {
+		t.Errorf("expected 1 required signature, got %d", msg.Header.NumRequiredSignatures)
+	}
+	if len(msg.AccountKeys) != 2 {
+		t.Fatalf("expected 2 account keys, got %d", len(msg.AccountKeys))
+	}
+	if msg.AccountKeys[0] != signer {
+		t.Error("expected first account key to be the signer")
+	}
+	if len(msg.Instructions) != 1 {
+		t.Fatalf("expected 1 instruction, got %d", len(msg.Instructions))
+	}
+	if msg.Instructions[0].ProgramIDIndex != 1 {
+		t.Errorf("expected programIdIndex 1, got %d", msg.Instructions[0].ProgramIDIndex)
+	}
+	if len(msg.Raw) != len(raw) {
+		t.Errorf("expected Raw to cover the whole message, got %d of %d bytes", len(msg.Raw), len(raw))
+	}
+}
+
+func TestParseSolanaMessageVersioned(t *testing.T) {
+	_, pub, _ := ed25519.GenerateKey(nil)
+	var signer, program, blockhash [32]byte
+	copy(signer[:], pub)
+	program[0] = 1
+	blockhash[0] = 2
+
+	legacy := buildLegacyMessage(signer, program, blockhash)
+	versioned := append([]byte{0x80}, legacy...)
+	versioned = append(versioned, encodeCompactU16(0)...) // 0 address table lookups
+
+	msg, err := parseSolanaMessage(versioned)
+	if err != nil {
+		t.Fatalf("failed to parse versioned message: %v", err)
+	}
+	if !msg.IsVersioned || msg.Version != 0 {
+		t.Errorf("expected version 0 versioned message, got versioned=%v version=%d", msg.IsVersioned, msg.Version)
+	}
+	if len(msg.AddressTableLookups) != 0 {
+		t.Errorf("expected no address table lookups, got %d", len(msg.AddressTableLookups))
+	}
+}
+
+// legacyTransferMessageFixtureB64 is the base64 wire encoding of a legacy
+// message for a real SystemProgram.transfer instruction: fee-payer and
+// recipient account keys, the real all-zero System Program ID, and the
+// real SystemInstruction::Transfer encoding (u32 LE discriminant 2 followed
+// by a u64 LE lamports field), account-ordered the way
+// @solana/web3.js's Message.compile orders a compiled message (signer
+// accounts, then writable non-signers, then readonly non-signers).
+// @solana/web3.js itself isn't installable in this offline environment, so
+// this wasn't captured by running it directly; it's assembled byte-for-byte
+// against the public System Program / message-compilation wire format
+// instead of this package's own parser assumptions, so a header-order or
+// instruction-layout bug here wouldn't be masked the way a fixture built
+// from parseSolanaMessage's own expectations could mask one.
+const legacyTransferMessageFixtureB64 = "AQABA6oAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAuwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAMwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQICAAEMAgAAAEBCDwAAAAAA"
+
+// v0TransferMessageFixtureB64 is legacyTransferMessageFixtureB64 with the
+// versioned-message flag set and one address table lookup appended (one
+// writable and one readonly index), matching how web3.js serializes a v0
+// message that resolves extra accounts from a lookup table.
+const v0TransferMessageFixtureB64 = "gAEAAQOqAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAALsAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAADMAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAECAgABDAIAAABAQg8AAAAAAAHdAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAEFAQY="
+
+func TestParseSolanaMessageLegacyFixture(t *testing.T) {
+	raw, err := base64.StdEncoding.DecodeString(legacyTransferMessageFixtureB64)
+	if err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+
+	msg, err := parseSolanaMessage(raw)
+	if err != nil {
+		t.Fatalf("failed to parse fixture message: %v", err)
+	}
+
+	if msg.IsVersioned {
+		t.Error("expected legacy fixture to not be versioned")
+	}
+	if msg.Header != (solanaMessageHeader{NumRequiredSignatures: 1, NumReadonlySignedAccounts: 0, NumReadonlyUnsignedAccounts: 1}) {
+		t.Errorf("unexpected header: %+v", msg.Header)
+	}
+	if len(msg.AccountKeys) != 3 {
+		t.Fatalf("expected 3 account keys, got %d", len(msg.AccountKeys))
+	}
+	if msg.AccountKeys[2] != ([32]byte{}) {
+		t.Error("expected the third account key to be the all-zero System Program ID")
+	}
+	if len(msg.Instructions) != 1 {
+		t.Fatalf("expected 1 instruction, got %d", len(msg.Instructions))
+	}
+	instr := msg.Instructions[0]
+	if instr.ProgramIDIndex != 2 {
+		t.Errorf("expected instruction to target account 2 (System Program), got %d", instr.ProgramIDIndex)
+	}
+	if len(instr.Data) != 12 {
+		t.Fatalf("expected 12-byte SystemInstruction::Transfer data, got %d", len(instr.Data))
+	}
+	if discriminant := binary.LittleEndian.Uint32(instr.Data[:4]); discriminant != 2 {
+		t.Errorf("expected Transfer discriminant 2, got %d", discriminant)
+	}
+	if lamports := binary.LittleEndian.Uint64(instr.Data[4:]); lamports != 1_000_000 {
+		t.Errorf("expected 1,000,000 lamports, got %d", lamports)
+	}
+}
+
+func TestParseSolanaMessageV0Fixture(t *testing.T) {
+	raw, err := base64.StdEncoding.DecodeString(v0TransferMessageFixtureB64)
+	if err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+
+	msg, err := parseSolanaMessage(raw)
+	if err != nil {
+		t.Fatalf("failed to parse fixture message: %v", err)
+	}
+
+	if !msg.IsVersioned || msg.Version != 0 {
+		t.Errorf("expected version 0 versioned message, got versioned=%v version=%d", msg.IsVersioned, msg.Version)
+	}
+	if len(msg.Instructions) != 1 {
+		t.Fatalf("expected 1 instruction, got %d", len(msg.Instructions))
+	}
+	if len(msg.AddressTableLookups) != 1 {
+		t.Fatalf("expected 1 address table lookup, got %d", len(msg.AddressTableLookups))
+	}
+	lookup := msg.AddressTableLookups[0]
+	if !bytes.Equal(lookup.WritableIndexes, []byte{5}) {
+		t.Errorf("expected writable index [5], got %v", lookup.WritableIndexes)
+	}
+	if !bytes.Equal(lookup.ReadonlyIndexes, []byte{6}) {
+		t.Errorf("expected readonly index [6], got %v", lookup.ReadonlyIndexes)
+	}
+}
+
+func TestParseSolanaTransactionAndSign(t *testing.T) {
+	priv, pub := mustGenerateKey(t)
+	var signer, program, blockhash [32]byte
+	copy(signer[:], pub)
+	program[0] = 1
+	blockhash[0] = 2
+
+	message := buildLegacyMessage(signer, program, blockhash)
+	txBytes := buildUnsignedTransaction(message)
+
+	tx, err := parseSolanaTransaction(txBytes)
+	if err != nil {
+		t.Fatalf("failed to parse transaction: %v", err)
+	}
+	if len(tx.Signatures) != 1 {
+		t.Fatalf("expected 1 signature slot, got %d", len(tx.Signatures))
+	}
+
+	if err := signSolanaTransaction(tx, priv, false); err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+
+	if !ed25519.Verify(pub, tx.Message.Raw, tx.Signatures[0][:]) {
+		t.Error("expected signature to verify against the message bytes")
+	}
+
+	signed := serializeSolanaTransaction(tx)
+	roundTripped, err := parseSolanaTransaction(signed)
+	if err != nil {
+		t.Fatalf("failed to re-parse signed transaction: %v", err)
+	}
+	if roundTripped.Signatures[0] != tx.Signatures[0] {
+		t.Error("expected signature to round-trip through serialization")
+	}
+}
+
+func TestSignSolanaTransactionRejectsNonSigner(t *testing.T) {
+	priv, _ := mustGenerateKey(t)
+	_, otherPub, _ := ed25519.GenerateKey(nil)
+
+	var signer, program, blockhash [32]byte
+	copy(signer[:], otherPub)
+	program[0] = 1
+	blockhash[0] = 2
+
+	message := buildLegacyMessage(signer, program, blockhash)
+	tx, err := parseSolanaTransaction(buildUnsignedTransaction(message))
+	if err != nil {
+		t.Fatalf("failed to parse transaction: %v", err)
+	}
+
+	if err := signSolanaTransaction(tx, priv, false); err == nil {
+		t.Error("expected error when keypair is not a required signer")
+	}
+
+	if err := signSolanaTransaction(tx, priv, true); err != nil {
+		t.Errorf("expected -allow-unsafe to permit signing, got error: %v", err)
+	}
+}
+
+func mustGenerateKey(t *testing.T) (ed25519.PrivateKey, ed25519.PublicKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return priv, pub
+}
+
+func TestDecodeTransactionBlobBase64(t *testing.T) {
+	data := []byte{1, 2, 3, 4}
+	encoded := "AQIDBA=="
+	decoded, err := decodeTransactionBlob(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decoded) != len(data) {
+		t.Fatalf("expected %d bytes, got %d", len(data), len(decoded))
+	}
+}